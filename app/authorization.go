@@ -82,14 +82,10 @@ func (a Authorization) init(ctx iris.Context) Authorization {
 	return a
 }
 
-// header 方法设置跨域资源共享（CORS）相关的响应头，并根据是否是刷新令牌来添加相应的令牌信息。
-// 该方法不接受参数，也不返回值。
+// header 方法根据是否是刷新令牌来添加相应的令牌信息。
+// 跨域相关的响应头由 Service.Cors 配置的跨域中间件统一设置。
 // 其中，a 是 Authorization 类型的实例，该实例包含处理请求所需的上下文信息和认证相关的数据。
 func (a Authorization) header() {
-	// 设置允许的请求头，这些头部信息会在浏览器中暴露给前端。
-	a.ctx.Header("Access-Control-Allow-Headers", "Refresh-Token, Accept-Version, Authorization, Access-Token, Language, Access-Control-Allow-Methods, Access-Control-Allow-Origin, Cache-Control, Content-Type, if-match, if-modified-since, if-none-match, if-unmodified-since, X-Requested-With")
-	// 设置允许浏览器访问的响应头，这些头部信息对于前端通过 AJAX 请求获取非常重要。
-	a.ctx.Header("Access-Control-Expose-Headers", "Authorization, Access-Token, Refresh-Token, Refresh-Expires")
 	// 根据是否是刷新令牌，设置相应的令牌信息到响应头。
 	if a.isRefresh {
 		// 如果是刷新令牌，则设置刷新令牌和过期时间。
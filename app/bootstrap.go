@@ -1,54 +1,98 @@
 package app
 
 import (
+	`context`
 	`fmt`
 	`os`
+	`os/signal`
 	`strings`
+	`sync`
+	`sync/atomic`
+	`syscall`
+	`time`
 	
 	`github.com/gookit/goutil/fsutil`
 	`github.com/kataras/iris/v12`
+	irisContext `github.com/kataras/iris/v12/context`
 	`github.com/kataras/iris/v12/hero`
 	`github.com/kataras/iris/v12/mvc`
 )
 
-type Bootstrap struct {
-	Global Global
-	m      *mvc.Application
-	app    *iris.Application
-}
+type (
+	// state 在 Bootstrap 的各个副本之间共享的运行状态
+	state struct {
+		closing    atomic.Bool            // closing 应用是否正在优雅关闭
+		registered atomic.Bool            // registered 模块是否已经注册
+		file       string                 // file 配置文件路径，重新加载时使用
+		event      EventInterface         // event 加载配置时触发的事件，重新加载时使用
+		current    atomic.Pointer[Global] // current 当前生效的配置，重新加载后替换
+		mutex      sync.Mutex             // mutex 保证同一时间只有一次重新加载
+	}
+	
+	Bootstrap struct {
+		Global   Global
+		m        *mvc.Application
+		app      *iris.Application
+		state    *state                           // state 共享的运行状态
+		modules  []Module                         // modules 通过 Use 注册的模块
+		shutdown []func(global Global)            // shutdown 应用关闭时依次执行的钩子函数
+		reload   []func(previous, current Global) // reload 配置重新加载后依次执行的钩子函数
+	}
+)
 
-// New 创建一个新的Bootstrap实例。
+// New 创建一个新的Bootstrap实例，并打开Redis、数据库和命名数据库连接，任一连接失败时返回错误。
 //
 // 参数:
-// - file: 指定配置文件的路径。
-// - isJson: 标识配置文件的格式是否为JSON。如果是true，则读取JSON格式的配置文件；否则，读取XML格式的配置文件。
+// - file: 指定配置文件的路径，根据扩展名或文件内容识别 XML、JSON、YAML 或 TOML 格式。
 // - event: 事件接口，用于在处理配置文件时触发事件。
 //
 // 返回值:
 // - Bootstrap: 返回一个初始化好的Bootstrap实例。
 // - error: 如果在创建过程中遇到任何错误，则返回非nil的error。
-func New(file string, isJson bool, event EventInterface) (b Bootstrap, err error) {
-	var global Global
-	// 根据配置文件格式选择解析方法
-	if isJson {
-		global, err = JSON(file, event)
-	} else {
-		global, err = XML(file, event)
+func New(file string, event EventInterface) (b Bootstrap, err error) {
+	if b, err = Prepare(file, event); err != nil {
+		return
 	}
-	if err != nil {
+	// 初始化RDS和数据库连接，连接保存在配置共享的注册表中
+	if _, err = b.Global.Rds(); err != nil {
 		return
 	}
-	// 初始化RDS和数据库连接
-	global.rdx, err = global.Rds()
-	if err != nil {
+	if _, err = b.Global.Db(); err != nil {
 		return
 	}
-	global.db, err = global.Db()
+	for _, database := range b.Global.Databases {
+		if _, err = b.Global.DbNamed(database.Alias); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// Prepare 加载并校验配置，创建 iris 应用，但不打开数据库和Redis连接，连接在首次使用时打开。
+// 用于只需要注册路由而不需要访问依赖的场景，例如在 CI 中列出路由表。
+//
+// 参数:
+// - file: 指定配置文件的路径，根据扩展名或文件内容识别 XML、JSON、YAML 或 TOML 格式。
+// - event: 事件接口，用于在处理配置文件时触发事件。
+//
+// 返回值:
+// - Bootstrap: 返回一个初始化好的Bootstrap实例。
+// - error: 配置加载或校验失败时返回错误。
+func Prepare(file string, event EventInterface) (b Bootstrap, err error) {
+	// 根据配置文件格式选择解析方法
+	global, err := Load(file, event)
 	if err != nil {
 		return
 	}
-	// 注册全局英雄信息
-	hero.Register(global)
+	// 校验配置，一次返回所有问题
+	if err = global.Validate(); err != nil {
+		return
+	}
+	// 注册全局英雄信息，处理器每次获取的都是当前生效的配置
+	s := &state{file: file, event: event}
+	s.current.Store(&global)
+	hero.Register(s.dependency)
+	hero.Register(s.named)
 	// 创建一个新的Iris应用实例
 	app := iris.New()
 	// 配置日志
@@ -57,9 +101,17 @@ func New(file string, isJson bool, event EventInterface) (b Bootstrap, err error
 		return
 	}
 	app.Logger().SetOutput(logbook).SetLevel(global.Service.Log.Level)
+	// 允许跨域时在路由之前安装跨域中间件，预检请求无需注册 OPTIONS 路由
+	// 跨域配置可以在运行时重新加载，每个请求使用当前生效的配置
+	if global.Service.CrossDomain {
+		app.UseRouter(func(ctx iris.Context) {
+			s.global().Service.Cors.Handler()(ctx)
+		})
+	}
 	return Bootstrap{
 		Global: global,
 		app:    app,
+		state:  s,
 	}, nil
 }
 
@@ -92,10 +144,11 @@ func (b Bootstrap) Set(handle func(app *iris.Application, global Global)) Bootst
 // 返回值：
 //   Bootstrap - 返回 Bootstrap 实例本身，支持链式调用。
 func (b Bootstrap) Mvc(handle func(app *mvc.Application)) Bootstrap {
-	b.m = mvc.New(b.app)   // 创建一个新的 mvc 应用实例
-	b.m.Register(b.Global) // 注册全局中间件
-	handle(b.m)            // 允许调用者进一步配置 mvc 应用
-	return b               // 支持链式调用
+	b.m = mvc.New(b.app)             // 创建一个新的 mvc 应用实例
+	b.m.Register(b.state.dependency) // 注册全局配置，控制器获取的是当前生效的配置
+	b.m.Register(b.state.named)      // 注册命名数据库连接
+	handle(b.m)                      // 允许调用者进一步配置 mvc 应用
+	return b                         // 支持链式调用
 }
 
 // View 方法用于设置视图引擎并注册函数到模板引擎中。
@@ -117,16 +170,168 @@ func (b Bootstrap) View(methods map[string]interface{}) Bootstrap {
 	return b
 }
 
-// Run 启动应用程序。
-// 该函数遍历全局服务配置中的资源目录，并根据存在与否处理静态资源目录和favicon。
-// 然后，它在指定的主机和端口上启动应用程序。
+// Use 按顺序注册模块。
+// 模块在应用启动时按依赖关系排序，先解码 modules 节点下的同名配置块，再调用 Register；
+// 应用关闭时按相反的顺序调用 Shutdown。
+// 返回值是 Bootstrap 结构体，允许链式调用。
+func (b Bootstrap) Use(modules ...Module) Bootstrap {
+	b.modules = append(b.modules, modules...)
+	return b
+}
+
+// resolve 按依赖顺序返回模块，并将配置块解码到模块配置中。
+func (b Bootstrap) resolve() (modules []Module, err error) {
+	if modules, err = resolve(b.modules); err != nil {
+		return
+	}
+	for _, module := range modules {
+		if config := module.Config(); config != nil {
+			if _, err = b.Global.Modules.Decode(module.Name(), config); err != nil {
+				return
+			}
+		}
+	}
+	return
+}
+
+// register 注册所有模块，多次调用只会注册一次。
+func (b Bootstrap) register() (err error) {
+	if !b.state.registered.CompareAndSwap(false, true) {
+		return
+	}
+	modules, err := b.resolve()
+	if err != nil {
+		return
+	}
+	for _, module := range modules {
+		if err = module.Register(b.app, b.Global); err != nil {
+			return fmt.Errorf("module %s register: %w", module.Name(), err)
+		}
+	}
+	return
+}
+
+// Migrate 按依赖顺序执行所有模块的数据迁移。
+//
+// 返回值:
+// error: 第一个迁移失败的模块返回的错误。
+func (b Bootstrap) Migrate() (err error) {
+	modules, err := b.resolve()
+	if err != nil {
+		return
+	}
+	for _, module := range modules {
+		if err = module.Migrate(b.Global); err != nil {
+			return fmt.Errorf("module %s migrate: %w", module.Name(), err)
+		}
+	}
+	return
+}
+
+// Routes 注册模块后返回应用的路由表，用于在不启动服务的情况下查看已注册的路由。
+//
+// 返回值:
+// []irisContext.RouteReadOnly: 已注册的路由。
+// error: 模块注册失败时返回错误。
+func (b Bootstrap) Routes() ([]irisContext.RouteReadOnly, error) {
+	if err := b.register(); err != nil {
+		return nil, err
+	}
+	return b.app.GetRoutesReadOnly(), nil
+}
+
+// OnShutdown 注册应用关闭时执行的钩子函数。
+// 钩子在处理中的请求完成之后、数据库和Redis连接关闭之前按注册顺序执行。
+// 返回值是 Bootstrap 结构体，允许链式调用。
+func (b Bootstrap) OnShutdown(handle func(global Global)) Bootstrap {
+	b.shutdown = append(b.shutdown, handle)
+	return b
+}
+
+// Closing 返回应用是否正在优雅关闭，关闭开始后 /readyz 返回 503。
+func (b Bootstrap) Closing() bool {
+	return b.state.closing.Load()
+}
+
+// graceful 等待 ctx 结束后优雅关闭应用。
+// 关闭顺序为：标记为正在关闭，等待 Service.ShutdownDelay 秒让负载均衡发现 /readyz 失败并摘除实例，
+// 停止接收新连接并在 Service.Shutdown 秒内等待处理中的请求完成，
+// 执行关闭钩子，按相反顺序关闭模块，最后关闭数据库和Redis连接，完成后关闭 done 通道。
+func (b Bootstrap) graceful(ctx context.Context, done chan<- struct{}) {
+	defer close(done)
+	<-ctx.Done()
+	b.app.Logger().Infof("%v, shutting down", context.Cause(ctx))
+	// 标记为正在关闭，就绪检查随即返回失败
+	b.state.closing.Store(true)
+	global := b.Current()
+	// 关闭监听之前继续处理请求，等待负载均衡摘除实例
+	if global.Service.ShutdownDelay > 0 {
+		time.Sleep(time.Duration(global.Service.ShutdownDelay) * time.Second)
+	}
+	// 等待处理中的请求，超过期限后强制关闭
+	wait := context.Background()
+	if global.Service.Shutdown > 0 {
+		var cancel context.CancelFunc
+		wait, cancel = context.WithTimeout(wait, time.Duration(global.Service.Shutdown)*time.Second)
+		defer cancel()
+	}
+	if err := b.app.Shutdown(wait); err != nil {
+		b.app.Logger().Error(err)
+	}
+	// 执行关闭钩子
+	for _, handle := range b.shutdown {
+		handle(global)
+	}
+	// 按注册的相反顺序关闭模块
+	if modules, err := resolve(b.modules); err == nil {
+		for i := len(modules) - 1; i >= 0; i-- {
+			if err = modules[i].Shutdown(global); err != nil {
+				b.app.Logger().Errorf("module %s shutdown: %v", modules[i].Name(), err)
+			}
+		}
+	}
+	// 关闭数据库和Redis连接
+	if err := b.Global.Close(); err != nil {
+		b.app.Logger().Error(err)
+	}
+}
+
+// Run 启动应用程序，收到 SIGINT/SIGTERM 时优雅关闭，启动失败时 panic。
 func (b Bootstrap) Run(config iris.Configuration) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := b.Start(ctx, config); err != nil {
+		panic(err)
+	}
+}
+
+// Start 启动应用程序并阻塞到 ctx 结束后的优雅关闭流程执行完毕。
+// 该函数遍历全局服务配置中的资源目录，并根据存在与否处理静态资源目录和favicon，
+// 然后在指定的主机和端口上启动应用程序。
+//
+// 参数:
+// ctx context.Context: 结束时开始优雅关闭，Run 使用 SIGINT/SIGTERM 信号结束。
+// config iris.Configuration: iris 配置。
+//
+// 返回值:
+// error: 模块注册失败或监听失败时返回错误。
+func (b Bootstrap) Start(ctx context.Context, config iris.Configuration) error {
+	// 注册通过 Use 添加的模块
+	if err := b.register(); err != nil {
+		return err
+	}
 	// 遍历配置的资源，如果目录存在，则将目录绑定到相应的URL上
 	for _, resource := range b.Global.Service.Resources {
 		if fsutil.PathExists(resource.Dir) {
 			b.app.HandleDir(resource.Url, resource.Dir)
 		}
 	}
+	// 本地存储的上传目录未在资源中配置时，同样映射到上传访问路径
+	if upload := b.Global.Service.Upload; upload.Local() && !b.Global.Service.mapped(upload.Resource.Url) {
+		if dir := os.ExpandEnv(upload.Resource.Dir); fsutil.PathExists(dir) {
+			b.app.HandleDir(upload.Resource.Url, dir)
+		}
+	}
 	// 检查Favicon文件是否存在，并设置
 	if fsutil.FileExist(b.Global.Service.Favicon) {
 		b.app.Favicon(b.Global.Service.Favicon)
@@ -135,12 +340,25 @@ func (b Bootstrap) Run(config iris.Configuration) {
 	config.PostMaxMemory = b.Global.Service.Upload.Maximum << 20
 	config.TimeFormat = "2006-01-02 15:04:05"
 	config.Charset = "UTF-8"
+	// 由 graceful 接管中断信号
+	config.DisableInterruptHandler = true
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	done := make(chan struct{})
+	go b.graceful(ctx, done)
+	// 监听配置文件变化和 SIGHUP 信号，关闭后停止
+	go b.watch(done)
 	// 启动应用监听指定的主机和端口
 	err := b.app.Run(
 		iris.Addr(fmt.Sprintf("%s:%d", b.Global.Service.Host, b.Global.Service.Port)),
 		iris.WithConfiguration(config),
+		iris.WithoutServerError(iris.ErrServerClosed),
 	)
 	if err != nil {
-		panic(err) // 如果启动过程中出现错误，则抛出panic
+		// 监听失败时同样执行关闭流程，释放连接
+		cancel(err)
 	}
+	// 等待关闭流程执行完毕
+	<-done
+	return err
 }
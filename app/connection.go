@@ -0,0 +1,114 @@
+package app
+
+import (
+	`errors`
+	`fmt`
+	`strconv`
+	`strings`
+	`sync`
+	`time`
+	
+	`github.com/go-redis/redis`
+	`gorm.io/gorm`
+)
+
+// recheck 缓存的Redis客户端超过该时长未检查时，获取前重新检查连接
+const recheck = 10 * time.Second
+
+type (
+	// connections 数据库和Redis连接注册表，由 GlobalDefault 创建，Global 的所有副本共享同一个注册表。
+	// 连接按照连接参数缓存，参数相同的副本得到同一个连接池。
+	connections struct {
+		mutex sync.Mutex              // mutex 保证每个连接只打开一次
+		db    map[string]*gorm.DB     // db 数据库连接，键为主库和副本的连接字符串
+		rdx   map[string]*redisClient // rdx Redis客户端，键为连接地址、数据库索引和密码
+	}
+	
+	// redisClient 缓存的Redis客户端
+	redisClient struct {
+		client  *redis.Client // client Redis客户端
+		checked time.Time     // checked 最近一次检查连接成功的时间
+	}
+)
+
+// newConnections 创建空的连接注册表。
+func newConnections() *connections {
+	return &connections{db: make(map[string]*gorm.DB), rdx: make(map[string]*redisClient)}
+}
+
+// registry 返回共享的连接注册表；没有注册表的配置（例如直接声明的 Global{}）每次返回新的注册表，连接不会被缓存。
+func (g Global) registry() *connections {
+	if g.conn == nil {
+		return newConnections()
+	}
+	return g.conn
+}
+
+// retry 执行 open，失败时按照重试配置等待后重试，等待时长每次加倍且不超过 MaxDelay。
+func (r Retry) retry(open func() error) (err error) {
+	delay := time.Duration(r.Delay) * time.Millisecond
+	maximum := time.Duration(r.MaxDelay) * time.Millisecond
+	for attempt := uint(0); ; attempt++ {
+		if err = open(); err == nil || attempt >= r.Attempts {
+			return
+		}
+		time.Sleep(delay)
+		if delay *= 2; maximum > 0 && delay > maximum {
+			delay = maximum
+		}
+	}
+}
+
+// ping 检查Redis连接，返回结果不是 PONG 时视为连接错误。
+func ping(client *redis.Client) error {
+	pong, err := client.Ping().Result()
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(pong, "PONG") {
+		return errors.New("redis connection error")
+	}
+	return nil
+}
+
+// key 返回数据库连接在注册表中的键，包含驱动、主库和所有副本的连接字符串。
+func (c MySQL) key() string {
+	keys := []string{c.driver() + ":" + c.DSN(), c.Policy}
+	for _, replica := range c.Replicas {
+		keys = append(keys, c.replica(replica).DSN()+"*"+strconv.Itoa(replica.Weight))
+	}
+	return strings.Join(keys, "|")
+}
+
+// key 返回Redis客户端在注册表中的键。
+func (c Redis) key() string {
+	return fmt.Sprintf("%s:%d/%d#%s", c.Host, c.Port, c.Db, c.Auth)
+}
+
+// Close 关闭注册表中的所有数据库和Redis连接，之后再次获取时重新打开。
+//
+// 返回值:
+// error: 关闭过程中遇到的所有错误，全部关闭成功时返回nil。
+func (g Global) Close() (err error) {
+	if g.conn == nil {
+		return
+	}
+	g.conn.mutex.Lock()
+	defer g.conn.mutex.Unlock()
+	var errs []error
+	// 关闭数据库连接池
+	for dsn, db := range g.conn.db {
+		sqlDB, e := db.DB()
+		if e == nil {
+			e = sqlDB.Close()
+		}
+		errs = append(errs, e)
+		delete(g.conn.db, dsn)
+	}
+	// 关闭Redis客户端
+	for key, rdx := range g.conn.rdx {
+		errs = append(errs, rdx.client.Close())
+		delete(g.conn.rdx, key)
+	}
+	return errors.Join(errs...)
+}
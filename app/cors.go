@@ -0,0 +1,103 @@
+package app
+
+import (
+	`net/http`
+	`strconv`
+	`strings`
+	
+	`github.com/kataras/iris/v12`
+)
+
+// allow 判断请求来源是否在允许的来源列表中。
+// 来源支持精确匹配、* 匹配任意来源，以及 https://*.example.com 形式的通配匹配；允许携带凭证时 * 不匹配任何来源。
+func (c Cors) allow(origin string) bool {
+	for _, value := range c.Origins {
+		if value == "*" {
+			if !c.Credentials {
+				return true
+			}
+			continue
+		}
+		if strings.EqualFold(value, origin) {
+			return true
+		}
+		if index := strings.Index(value, "*"); index >= 0 {
+			prefix, suffix := value[:index], value[index+1:]
+			if len(origin) >= len(prefix)+len(suffix) &&
+				strings.HasPrefix(strings.ToLower(origin), strings.ToLower(prefix)) &&
+				strings.HasSuffix(strings.ToLower(origin), strings.ToLower(suffix)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// wildcard 判断列表中是否包含 *。
+func wildcard(values []string) bool {
+	for _, value := range values {
+		if value == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// Handler 根据跨域配置生成跨域中间件。
+// 对于预检请求(OPTIONS 且携带 Access-Control-Request-Method)，中间件直接返回 204 并终止后续处理；
+// 对于普通跨域请求，中间件设置允许的来源和暴露的响应头后继续执行后续处理。
+// 来源不被允许时不设置任何跨域响应头，预检请求返回 403。
+func (c Cors) Handler() iris.Handler {
+	methods := strings.Join(c.Methods, ", ")
+	headers := strings.Join(c.Headers, ", ")
+	expose := strings.Join(c.Expose, ", ")
+	return func(ctx iris.Context) {
+		origin := ctx.GetHeader("Origin")
+		preflight := ctx.Method() == http.MethodOptions && ctx.GetHeader("Access-Control-Request-Method") != ""
+		if preflight {
+			ctx.Header("Vary", "Origin, Access-Control-Request-Method, Access-Control-Request-Headers")
+		} else {
+			ctx.Header("Vary", "Origin")
+		}
+		// 非跨域请求直接放行
+		if origin == "" {
+			ctx.Next()
+			return
+		}
+		if !c.allow(origin) {
+			if preflight {
+				ctx.StopWithStatus(http.StatusForbidden)
+				return
+			}
+			ctx.Next()
+			return
+		}
+		// 携带凭证时浏览器不接受 *，回显匹配的具体来源
+		if wildcard(c.Origins) && !c.Credentials {
+			ctx.Header("Access-Control-Allow-Origin", "*")
+		} else {
+			ctx.Header("Access-Control-Allow-Origin", origin)
+		}
+		if c.Credentials {
+			ctx.Header("Access-Control-Allow-Credentials", "true")
+		}
+		if !preflight {
+			if len(expose) != 0 {
+				ctx.Header("Access-Control-Expose-Headers", expose)
+			}
+			ctx.Next()
+			return
+		}
+		// 预检请求
+		ctx.Header("Access-Control-Allow-Methods", methods)
+		if wildcard(c.Headers) {
+			ctx.Header("Access-Control-Allow-Headers", ctx.GetHeader("Access-Control-Request-Headers"))
+		} else if len(headers) != 0 {
+			ctx.Header("Access-Control-Allow-Headers", headers)
+		}
+		if c.MaxAge > 0 {
+			ctx.Header("Access-Control-Max-Age", strconv.Itoa(c.MaxAge))
+		}
+		ctx.StopWithStatus(http.StatusNoContent)
+	}
+}
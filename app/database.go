@@ -0,0 +1,78 @@
+package app
+
+import (
+	`fmt`
+	`reflect`
+	
+	`github.com/kataras/iris/v12`
+	`gorm.io/gorm`
+)
+
+// DatabaseDefault 默认连接的名称，对应 mysql 配置块
+const DatabaseDefault = "default"
+
+// Named 命名数据库连接的获取器，可以注入到 hero 和 mvc 的处理器中，每个请求使用当前生效的配置。
+//
+//	func (c *Controller) GetReport(named app.Named) {
+//		db, err := named.Db("report")
+//	}
+type Named struct {
+	global Global // global 当前生效的配置
+}
+
+// Db 返回指定名称的数据库连接，名称为空或 default 时返回默认连接。
+//
+// 参数:
+// name string: 连接名称，对应 databases 中的 alias。
+//
+// 返回值:
+// *gorm.DB: 数据库连接实例。
+// error: 名称不存在或连接失败时返回错误。
+func (n Named) Db(name string) (*gorm.DB, error) {
+	return n.global.DbNamed(name)
+}
+
+// Database 返回指定名称的数据库配置，命名连接中未配置的项与默认连接相同，只读副本不会继承。
+//
+// 参数:
+// name string: 连接名称，名称为空或 default 时返回 mysql 配置。
+//
+// 返回值:
+// MySQL: 合并后的数据库配置。
+// error: 名称不存在时返回错误。
+func (g Global) Database(name string) (config MySQL, err error) {
+	if name == "" || name == DatabaseDefault {
+		return g.MySQL, nil
+	}
+	for _, database := range g.Databases {
+		if database.Alias != name {
+			continue
+		}
+		config = g.MySQL
+		config.Replicas = nil
+		deepMerge(reflect.ValueOf(&config).Elem(), reflect.ValueOf(database.MySQL))
+		return
+	}
+	return config, fmt.Errorf("database %q is not configured", name)
+}
+
+// DbNamed 返回指定名称的数据库连接，连接与默认连接一样缓存在共享的注册表中。
+//
+// 参数:
+// name string: 连接名称，名称为空或 default 时与 Db 相同。
+//
+// 返回值:
+// db *gorm.DB: 数据库连接实例。
+// err error: 名称不存在或连接失败时返回错误。
+func (g Global) DbNamed(name string) (db *gorm.DB, err error) {
+	config, err := g.Database(name)
+	if err != nil {
+		return
+	}
+	return g.open(config)
+}
+
+// named 作为 hero 和 mvc 的动态依赖，每个请求获取当前生效配置的命名数据库连接。
+func (s *state) named(ctx iris.Context) Named {
+	return Named{global: s.global()}
+}
@@ -0,0 +1,119 @@
+package app
+
+import (
+	`fmt`
+	`net/url`
+	`os`
+	`sort`
+	`strings`
+	
+	`github.com/glebarez/sqlite`
+	`gorm.io/driver/mysql`
+	`gorm.io/driver/postgres`
+	`gorm.io/gorm`
+)
+
+const (
+	DriverMySQL    = "mysql"    // DriverMySQL MySQL 数据库，未设置驱动时的默认值
+	DriverPostgres = "postgres" // DriverPostgres PostgreSQL 数据库
+	DriverSQLite   = "sqlite"   // DriverSQLite SQLite 数据库，使用纯 Go 实现，Name 为数据库文件路径或 :memory:
+)
+
+// sslModes PostgreSQL 的 sslmode 与 TLS 配置的对应关系
+var sslModes = map[string]string{
+	"":            "disable",
+	"false":       "disable",
+	"true":        "verify-full",
+	"skip-verify": "require",
+	"preferred":   "prefer",
+}
+
+// driver 返回数据库驱动名称，未设置时为 mysql。
+func (c MySQL) driver() string {
+	if c.Driver == "" {
+		return DriverMySQL
+	}
+	return strings.ToLower(c.Driver)
+}
+
+// memory 判断是否为 SQLite 内存数据库。
+func (c MySQL) memory() bool {
+	return c.driver() == DriverSQLite && (c.Name == ":memory:" || strings.Contains(c.Name, "mode=memory"))
+}
+
+// DSN 根据驱动构造数据库连接字符串，MySQL 与 Dialect 相同。
+//
+// 返回值:
+// string: 当前驱动使用的连接字符串，PostgreSQL 为 key=value 格式，SQLite 为展开环境变量后的文件路径。
+func (c MySQL) DSN() string {
+	switch c.driver() {
+	case DriverPostgres:
+		return c.postgres()
+	case DriverSQLite:
+		return c.sqlite()
+	default:
+		return c.Dialect()
+	}
+}
+
+// postgres 构造PostgreSQL连接字符串，TLS 转换为 sslmode，Timezone 为 Local 时使用本地时区名称。
+func (c MySQL) postgres() string {
+	params := map[string]string{
+		"host":     c.Host,
+		"port":     fmt.Sprint(c.Port),
+		"user":     c.Username,
+		"password": c.Password,
+		"dbname":   c.Name,
+		"sslmode":  sslModes[strings.ToLower(c.TLS)],
+	}
+	if c.Timezone != "" && c.Timezone != "Local" {
+		params["TimeZone"] = c.Timezone
+	}
+	if c.Timeout > 0 {
+		params["connect_timeout"] = fmt.Sprint(c.Timeout)
+	}
+	// 追加其它参数，格式错误的参数由 Validate 报告
+	if values, err := url.ParseQuery(c.Params); err == nil {
+		for name := range values {
+			params[name] = values.Get(name)
+		}
+	}
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	pairs := make([]string, 0, len(names))
+	for _, name := range names {
+		value := strings.ReplaceAll(strings.ReplaceAll(params[name], `\`, `\\`), `'`, `\'`)
+		if value == "" || strings.ContainsAny(value, ` '\`) {
+			value = "'" + value + "'"
+		}
+		pairs = append(pairs, name+"="+value)
+	}
+	return strings.Join(pairs, " ")
+}
+
+// sqlite 构造SQLite连接字符串，Params 作为查询参数追加在文件路径之后。
+func (c MySQL) sqlite() string {
+	dsn := os.ExpandEnv(c.Name)
+	if c.Params == "" {
+		return dsn
+	}
+	if strings.Contains(dsn, "?") {
+		return dsn + "&" + c.Params
+	}
+	return dsn + "?" + c.Params
+}
+
+// dialector 根据驱动返回 gorm 使用的数据库方言。
+func (c MySQL) dialector(dsn string) gorm.Dialector {
+	switch c.driver() {
+	case DriverPostgres:
+		return postgres.Open(dsn)
+	case DriverSQLite:
+		return sqlite.Open(dsn)
+	default:
+		return mysql.Open(dsn)
+	}
+}
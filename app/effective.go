@@ -0,0 +1,67 @@
+package app
+
+import (
+	`sort`
+	`strings`
+	
+	`github.com/chaodoing/figure/o`
+	`github.com/kataras/iris/v12`
+)
+
+type (
+	// Setting 单个配置项的有效值及其来源
+	Setting struct {
+		Name   string `json:"name" xml:"name" yaml:"Name" comment:"配置项路径"`                // Name 以 . 分隔的 json 标签路径
+		Value  string `json:"value" xml:"value" yaml:"Value" comment:"有效值, 敏感字段已脱敏"` // Value 脱敏后的有效值，列表以 , 分隔
+		Source string `json:"source" xml:"source" yaml:"Source" comment:"值的来源"`            // Source 值的来源
+		Env    string `json:"env" xml:"env" yaml:"Env" comment:"可以覆盖该配置项的环境变量"`   // Env 可以覆盖该配置项的环境变量
+	}
+	
+	// Effective 当前进程使用的有效配置
+	Effective struct {
+		Config   Global    `json:"config" xml:"root" yaml:"Config" comment:"脱敏后的有效配置"`             // Config 脱敏后的有效配置，XML 元素名称由 Global.XMLName 决定
+		Settings []Setting `json:"settings" xml:"settings" yaml:"Settings" comment:"每个配置项的值和来源"` // Settings 每个配置项的值和来源，按名称排序
+	}
+)
+
+// Effective 返回脱敏后的有效配置，每个配置项附带来源和对应的环境变量名称。
+// 没有经过 Load 加载的配置来源为空。
+func (g Global) Effective() Effective {
+	redacted := g.Redact()
+	environ := g.Environ()
+	values := leaves(redacted)
+	settings := make([]Setting, 0, len(values))
+	for name, value := range values {
+		settings = append(settings, Setting{
+			Name:   name,
+			Value:  strings.ReplaceAll(value, "\x00", ","),
+			Source: g.Source(name),
+			Env:    environ[name],
+		})
+	}
+	sort.Slice(settings, func(i, j int) bool {
+		return settings[i].Name < settings[j].Name
+	})
+	return Effective{Config: redacted, Settings: settings}
+}
+
+// Effective 注册查看有效配置的接口，通过 o.O 输出当前生效的脱敏配置以及每个配置项的来源。
+// 接口不提供默认的访问控制：本机的反向代理转发的请求同样来自本机地址，因此必须传入登录或权限校验的中间件。
+//
+// 参数:
+// route string: 接口的路由，例如 /debug/config。
+// guard iris.Handler: 访问控制中间件，校验失败时应结束请求，不能为 nil。
+// handlers ...iris.Handler: 在 guard 之后、接口之前执行的其它中间件。
+//
+// 返回值是 Bootstrap 结构体，允许链式调用。
+func (b Bootstrap) Effective(route string, guard iris.Handler, handlers ...iris.Handler) Bootstrap {
+	if guard == nil {
+		panic("effective config route " + route + " requires an access guard") // 不允许在没有访问控制的情况下暴露配置
+	}
+	handlers = append([]iris.Handler{guard}, handlers...)
+	handlers = append(handlers, func(ctx iris.Context) {
+		o.O(ctx, o.Data{Code: 0, Message: "OK", Data: b.Current().Effective()})
+	})
+	b.app.Get(route, handlers...)
+	return b
+}
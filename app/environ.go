@@ -0,0 +1,259 @@
+package app
+
+import (
+	`fmt`
+	`os`
+	`reflect`
+	`strconv`
+	`strings`
+)
+
+const (
+	EnvPrefix = "FIGURE_" // EnvPrefix 覆盖配置的环境变量前缀
+	
+	SourceDefault = "default"  // SourceDefault 值来自 GlobalDefault
+	SourceFile    = "file"     // SourceFile 值来自配置文件
+	SourceProfile = "profile"  // SourceProfile 值来自当前环境的配置文件，例如 app.development.xml
+	SourceEnvFile = ".env.yml" // SourceEnvFile 值来自 ${DIR}/.env.yml
+	SourceEnv     = "env"      // SourceEnv 值来自 FIGURE_ 开头的环境变量
+)
+
+// sources 记录每个配置项的来源，通过比较每一层合并前后的值确定。
+type sources struct {
+	values map[string]string // values 当前每个配置项的值
+	origin map[string]string // origin 每个配置项的来源
+}
+
+// newSources 以默认配置为基础创建来源记录。
+func newSources(global Global) *sources {
+	s := &sources{values: leaves(global), origin: make(map[string]string)}
+	for name := range s.values {
+		s.origin[name] = SourceDefault
+	}
+	return s
+}
+
+// mark 将与上一层相比发生变化的配置项标记为 source，值相同时保留上一层的来源。
+func (s *sources) mark(global Global, source string) {
+	values := leaves(global)
+	for name, value := range values {
+		if previous, ok := s.values[name]; !ok || previous != value {
+			s.origin[name] = source
+		}
+	}
+	for name := range s.origin {
+		if _, ok := values[name]; !ok {
+			delete(s.origin, name)
+		}
+	}
+	s.values = values
+}
+
+// Sources 返回每个配置项的来源，键为以 . 分隔的 json 标签路径，例如 service.port、service.resources.0.dir。
+// 值为 SourceDefault、SourceFile、SourceProfile、SourceEnvFile 或 SourceEnv；未经过 Load 加载的配置返回 nil。
+func (g Global) Sources() map[string]string {
+	if g.sources == nil {
+		return nil
+	}
+	data := make(map[string]string, len(g.sources))
+	for name, source := range g.sources {
+		data[name] = source
+	}
+	return data
+}
+
+// Source 返回单个配置项的来源，配置项不存在时返回空字符串。
+func (g Global) Source(name string) string {
+	return g.sources[name]
+}
+
+// Environ 返回所有配置项对应的环境变量名称，键为以 . 分隔的 json 标签路径。
+// 结构体切片只列出已有的元素，新增元素可以使用下一个下标，例如 FIGURE_SERVICE_RESOURCES_2_DIR。
+func (g Global) Environ() map[string]string {
+	data := make(map[string]string)
+	for name := range leaves(g) {
+		data[name] = EnvPrefix + strings.ToUpper(strings.ReplaceAll(name, ".", "_"))
+	}
+	return data
+}
+
+// tagName 返回字段的 json 标签名称，忽略的字段返回空字符串。
+func tagName(field reflect.StructField) string {
+	if field.PkgPath != "" {
+		return ""
+	}
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		name = strings.ToLower(field.Name)
+	}
+	return name
+}
+
+// leaves 将配置展开为 路径 => 值，标量切片作为一个配置项，字符串中的环境变量会被展开以便比较。
+func leaves(global Global) map[string]string {
+	data := make(map[string]string)
+	var walk func(prefix string, value reflect.Value)
+	walk = func(prefix string, value reflect.Value) {
+		switch value.Kind() {
+		case reflect.Struct:
+			for i := 0; i < value.NumField(); i++ {
+				if name := tagName(value.Type().Field(i)); name != "" {
+					walk(strings.TrimPrefix(prefix+"."+name, "."), value.Field(i))
+				}
+			}
+		case reflect.Slice:
+			if value.Type().Elem().Kind() == reflect.Struct {
+				for i := 0; i < value.Len(); i++ {
+					walk(prefix+"."+strconv.Itoa(i), value.Index(i))
+				}
+				return
+			}
+			items := make([]string, value.Len())
+			for i := range items {
+				items[i] = os.ExpandEnv(fmt.Sprint(value.Index(i).Interface()))
+			}
+			data[prefix] = strings.Join(items, "\x00")
+		case reflect.Map, reflect.Interface, reflect.Pointer:
+			// 模块配置等动态内容不参与展开
+		default:
+			data[prefix] = os.ExpandEnv(fmt.Sprint(value.Interface()))
+		}
+	}
+	walk("", reflect.ValueOf(global))
+	return data
+}
+
+// environ 使用 FIGURE_ 开头的环境变量覆盖配置。
+// 变量名称由 json 标签路径转换为大写并以 _ 连接，例如 FIGURE_MYSQL_PASSWORD、FIGURE_SERVICE_PORT；
+// 标量切片使用 delim 标签指定的分隔符（默认为 ,）分隔，结构体切片使用下标，例如 FIGURE_SERVICE_RESOURCES_0_DIR。
+//
+// 参数:
+// variables []string: 形如 KEY=VALUE 的环境变量，通常为 os.Environ()。
+//
+// 返回值:
+// Global: 覆盖后的配置。
+// error: 环境变量的值无法转换为字段类型时返回错误。
+func (g Global) environ(variables []string) (Global, error) {
+	env := make(map[string]string)
+	var names []string
+	for _, pair := range variables {
+		if name, value, _ := strings.Cut(pair, "="); strings.HasPrefix(name, EnvPrefix) {
+			env[name] = value
+			names = append(names, name)
+		}
+	}
+	var walk func(name string, field reflect.StructField, value reflect.Value) error
+	walk = func(name string, field reflect.StructField, value reflect.Value) error {
+		switch value.Kind() {
+		case reflect.Struct:
+			for i := 0; i < value.NumField(); i++ {
+				child := value.Type().Field(i)
+				if tag := tagName(child); tag != "" {
+					if err := walk(name+"_"+strings.ToUpper(tag), child, value.Field(i)); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		case reflect.Map, reflect.Interface, reflect.Pointer:
+			return nil
+		case reflect.Slice:
+			if value.Type().Elem().Kind() == reflect.Struct {
+				return walkSlice(name, names, value, func(index string, item reflect.Value) error {
+					return walk(name+"_"+index, field, item)
+				})
+			}
+		}
+		text, ok := env[name]
+		if !ok {
+			return nil
+		}
+		if err := parseValue(text, field.Tag.Get("delim"), value); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		return nil
+	}
+	err := walk(strings.TrimSuffix(EnvPrefix, "_"), reflect.StructField{}, reflect.ValueOf(&g).Elem())
+	return g, err
+}
+
+// walkSlice 根据环境变量中出现的最大下标扩展结构体切片，并依次处理每个元素。
+// 切片会被复制后再修改，避免影响共享底层数组的其它配置副本。
+func walkSlice(name string, names []string, value reflect.Value, walk func(index string, item reflect.Value) error) error {
+	length := value.Len()
+	for _, key := range names {
+		rest, ok := strings.CutPrefix(key, name+"_")
+		if !ok {
+			continue
+		}
+		index, _, _ := strings.Cut(rest, "_")
+		if i, err := strconv.Atoi(index); err == nil && i >= length {
+			length = i + 1
+		}
+	}
+	if length == 0 {
+		return nil
+	}
+	items := reflect.MakeSlice(value.Type(), length, length)
+	reflect.Copy(items, value)
+	for i := 0; i < length; i++ {
+		if err := walk(strconv.Itoa(i), items.Index(i)); err != nil {
+			return err
+		}
+	}
+	value.Set(items)
+	return nil
+}
+
+// parseValue 将字符串转换为字段类型并赋值，标量切片按 delim 分隔。
+func parseValue(text, delim string, value reflect.Value) error {
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(text)
+	case reflect.Bool:
+		data, err := strconv.ParseBool(text)
+		if err != nil {
+			return err
+		}
+		value.SetBool(data)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		data, err := strconv.ParseInt(text, 10, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetInt(data)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		data, err := strconv.ParseUint(text, 10, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetUint(data)
+	case reflect.Float32, reflect.Float64:
+		data, err := strconv.ParseFloat(text, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetFloat(data)
+	case reflect.Slice:
+		if delim == "" {
+			delim = ","
+		}
+		var parts []string
+		if text != "" {
+			parts = strings.Split(text, delim)
+		}
+		items := reflect.MakeSlice(value.Type(), len(parts), len(parts))
+		for i, part := range parts {
+			if err := parseValue(strings.TrimSpace(part), "", items.Index(i)); err != nil {
+				return err
+			}
+		}
+		value.Set(items)
+	default:
+		return fmt.Errorf("unsupported type %s", value.Type())
+	}
+	return nil
+}
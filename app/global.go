@@ -1,23 +1,24 @@
 package app
 
 import (
+	`database/sql`
 	"encoding/xml"
 	`errors`
 	"fmt"
 	"io"
 	`log`
+	`net/url`
 	"os"
 	"path"
-	`strings`
 	"time"
 	
 	"github.com/go-redis/redis"
+	driver "github.com/go-sql-driver/mysql"
 	"github.com/gookit/goutil/fsutil"
 	"github.com/lestrrat-go/strftime"
 	encoder "github.com/zwgblue/yaml-encoder"
 	"gopkg.in/natefinch/lumberjack.v2"
 	"gopkg.in/yaml.v2"
-	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 	`gorm.io/gorm/logger`
 )
@@ -47,50 +48,128 @@ type (
 		Dir     string   `json:"dir" xml:"dir" yaml:"Dir" comment:"模板目录位置"`                         // Dir 模板目录位置
 		Ext     string   `json:"ext" xml:"ext" yaml:"Ext" comment:"模板文件扩展名称"`                     // Ext 模板文件扩展名称
 	}
+	// Cors 跨域资源共享配置
+	Cors struct {
+		Origins     []string `json:"origins" xml:"origins" yaml:"Origins" comment:"允许的来源, * 表示任意来源"`   // Origins 允许的来源
+		Methods     []string `json:"methods" xml:"methods" yaml:"Methods" comment:"允许的请求方法"`               // Methods 允许的请求方法
+		Headers     []string `json:"headers" xml:"headers" yaml:"Headers" comment:"允许的请求头"`                 // Headers 允许的请求头
+		Expose      []string `json:"expose" xml:"expose" yaml:"Expose" comment:"允许浏览器访问的响应头"`          // Expose 允许浏览器访问的响应头
+		Credentials bool     `json:"credentials" xml:"credentials" yaml:"Credentials" comment:"是否允许携带凭证"` // Credentials 是否允许携带凭证
+		MaxAge      int      `json:"max_age" xml:"maxAge" yaml:"MaxAge" comment:"预检请求缓存时长(秒)"`           // MaxAge 预检请求缓存时长
+	}
+	// S3 S3 兼容对象存储配置
+	S3 struct {
+		Endpoint  string `json:"endpoint" xml:"endpoint" yaml:"Endpoint" comment:"服务地址, 例如 127.0.0.1:9000"` // Endpoint 服务地址
+		Region    string `json:"region" xml:"region" yaml:"Region" comment:"存储区域"`                            // Region 存储区域
+		Bucket    string `json:"bucket" xml:"bucket" yaml:"Bucket" comment:"存储桶名称"`                          // Bucket 存储桶名称
+		AccessKey string `json:"access_key" xml:"accessKey" yaml:"AccessKey" comment:"访问密钥ID"`                // AccessKey 访问密钥ID
+		SecretKey string `json:"secret_key" xml:"secretKey" yaml:"SecretKey" secret:"true" comment:"访问密钥"`    // SecretKey 访问密钥
+		Secure    bool   `json:"secure" xml:"secure" yaml:"Secure" comment:"是否使用 HTTPS"`                      // Secure 是否使用 HTTPS
+		Url       string `json:"url" xml:"url" yaml:"Url" comment:"公开访问地址, 为空时使用服务地址和存储桶拼接"` // Url 公开访问地址
+		Expire    uint64 `json:"expire" xml:"expire" yaml:"Expire" comment:"预签名地址有效期(秒)"`                // Expire 预签名地址有效期
+	}
+	// Tus 断点续传配置
+	Tus struct {
+		Maximum int64  `json:"maximum" xml:"maximum" yaml:"Maximum" comment:"断点续传文件大小MB"`                     // Maximum 断点续传文件大小
+		Expire  uint64 `json:"expire" xml:"expire" yaml:"Expire" comment:"未完成上传的保留时长(秒)"`                  // Expire 未完成上传的保留时长
+		Dir     string `json:"dir" xml:"dir" yaml:"Dir" comment:"未完成上传的暂存目录, 不能位于对外访问的资源目录中"` // Dir 未完成上传的暂存目录，支持环境变量
+	}
+	// Upload 文件上传配置
 	Upload struct {
-		Maximum  int64    `json:"maximum" xml:"maximum" yaml:"Maximum" comment:"文件上传大小MB"` // Maximum 文件上传大小
-		Resource Resource `json:"resource" xml:"resource" yaml:"Resource" comment:"文件内容"`    // Resource 静态资源文件配置
+		Maximum    int64    `json:"maximum" xml:"maximum" yaml:"Maximum" comment:"文件上传大小MB"`                                // Maximum 文件上传大小
+		Resource   Resource `json:"resource" xml:"resource" yaml:"Resource" comment:"文件内容"`                                   // Resource 静态资源文件配置
+		Extensions []string `json:"extensions" xml:"extensions" yaml:"Extensions" comment:"允许上传的扩展名, 为空时不限制"`       // Extensions 允许上传的扩展名
+		Mimes      []string `json:"mimes" xml:"mimes" yaml:"Mimes" comment:"允许上传的文件类型, 支持 image/* 通配, 为空时不限制"` // Mimes 允许上传的文件类型
+		Driver     string   `json:"driver" xml:"driver" yaml:"Driver" comment:"存储驱动 local/s3"`                                // Driver 存储驱动
+		S3         S3       `json:"s3" xml:"s3" yaml:"S3" comment:"S3 兼容对象存储配置"`                                          // S3 对象存储配置
+		Tus        Tus      `json:"tus" xml:"tus" yaml:"Tus" comment:"断点续传配置"`                                              // Tus 断点续传配置
 	}
 	// Service iris应用配置
 	Service struct {
-		Favicon     string     `json:"favicon" xml:"favicon" yaml:"Favicon" comment:"网站图标配置"`                             // Favicon 网站图标配置
-		Port        uint16     `json:"port" xml:"port" yaml:"Port" comment:"监听端口"`                                          // Port 监听端口
-		Host        string     `json:"host" xml:"host" yaml:"Host" comment:"监听主机"`                                          // Host 监听主机
-		CrossDomain bool       `json:"cross_domain" xml:"crossDomain" yaml:"CrossDomain" comment:"允许跨域"`                    // CrossDomain 允许跨域
-		Log         Logger     `json:"log" xml:"log" yaml:"Log" comment:"日志配置 level:[disable fatal error warn info debug]"` // Log 日志配置
-		Template    Template   `json:"template" xml:"template" yaml:"Template" comment:"模板目录配置"`                          // Template 模板目录配置
-		Resources   []Resource `json:"resources" xml:"resources" yaml:"Resources" comment:"允许跨域"`                           // Resources 静态资源文件配置
-		Upload      Upload     `json:"upload" xml:"upload" yaml:"Upload" comment:"上传配置"`
+		Favicon       string     `json:"favicon" xml:"favicon" yaml:"Favicon" comment:"网站图标配置"`                             // Favicon 网站图标配置
+		Port          uint16     `json:"port" xml:"port" yaml:"Port" comment:"监听端口"`                                          // Port 监听端口
+		Host          string     `json:"host" xml:"host" yaml:"Host" comment:"监听主机"`                                          // Host 监听主机
+		CrossDomain   bool       `json:"cross_domain" xml:"crossDomain" yaml:"CrossDomain" comment:"允许跨域"`                    // CrossDomain 允许跨域
+		Cors          Cors       `json:"cors" xml:"cors" yaml:"Cors" comment:"跨域配置"`                                          // Cors 跨域配置
+		Log           Logger     `json:"log" xml:"log" yaml:"Log" comment:"日志配置 level:[disable fatal error warn info debug]"` // Log 日志配置
+		Template      Template   `json:"template" xml:"template" yaml:"Template" comment:"模板目录配置"`                          // Template 模板目录配置
+		Resources     []Resource `json:"resources" xml:"resources" yaml:"Resources" merge:"key:url" comment:"允许跨域"`           // Resources 静态资源文件配置
+		Upload        Upload     `json:"upload" xml:"upload" yaml:"Upload" comment:"上传配置"`
+		Shutdown      uint64     `json:"shutdown" xml:"shutdown" yaml:"Shutdown" comment:"优雅关闭等待时长(秒)"`                                     // Shutdown 优雅关闭等待时长
+		ShutdownDelay uint64     `json:"shutdown_delay" xml:"shutdownDelay" yaml:"ShutdownDelay" comment:"优雅关闭前就绪检查返回失败的等待时长(秒)"` // ShutdownDelay 就绪检查返回失败后、停止接收新连接前的等待时长，让负载均衡摘除实例
+		Watch         uint64     `json:"watch" xml:"watch" yaml:"Watch" comment:"配置文件检查间隔(秒), 0 表示只响应 SIGHUP"`                         // Watch 配置文件检查间隔
 	}
 	// Redis redis配置
 	Redis struct {
-		Host string `json:"host" xml:"host" yaml:"Host" comment:"连接主机"` // Host 连接主机
-		Port uint16 `json:"port" xml:"port" yaml:"Port" comment:"连接端口"` // Port 连接端口
-		Db   int    `json:"db" xml:"db" yaml:"Db" comment:"数据库索引"`     // Db 数据库索引
-		Auth string `json:"auth" xml:"auth" yaml:"Auth" comment:"连接密码"` // Auth 连接密码
-		TTL  uint64 `json:"ttl" xml:"ttl" yaml:"TTL" comment:"缓存时长"`    // TTL 缓存时长
+		Host  string `json:"host" xml:"host" yaml:"Host" comment:"连接主机"`               // Host 连接主机
+		Port  uint16 `json:"port" xml:"port" yaml:"Port" comment:"连接端口"`               // Port 连接端口
+		Db    int    `json:"db" xml:"db" yaml:"Db" comment:"数据库索引"`                   // Db 数据库索引
+		Auth  string `json:"auth" xml:"auth" yaml:"Auth" secret:"true" comment:"连接密码"` // Auth 连接密码
+		TTL   uint64 `json:"ttl" xml:"ttl" yaml:"TTL" comment:"缓存时长"`                  // TTL 缓存时长
+		Retry Retry  `json:"retry" xml:"retry" yaml:"Retry" comment:"连接重试配置"`        // Retry 连接重试配置
+	}
+	// Retry 首次连接失败时的重试配置
+	Retry struct {
+		Attempts uint   `json:"attempts" xml:"attempts" yaml:"Attempts" comment:"首次连接失败时的重试次数"`    // Attempts 重试次数
+		Delay    uint64 `json:"delay" xml:"delay" yaml:"Delay" comment:"首次重试等待时长(毫秒), 之后每次加倍"` // Delay 首次重试等待时长
+		MaxDelay uint64 `json:"max_delay" xml:"maxDelay" yaml:"MaxDelay" comment:"最长重试等待时长(毫秒)"`     // MaxDelay 最长重试等待时长
+	}
+	// Pool 数据库连接池配置
+	Pool struct {
+		MaxOpenConns    int    `json:"max_open_conns" xml:"maxOpenConns" yaml:"MaxOpenConns" comment:"最大打开连接数, 0 表示不限制"`                 // MaxOpenConns 最大打开连接数
+		MaxIdleConns    int    `json:"max_idle_conns" xml:"maxIdleConns" yaml:"MaxIdleConns" comment:"最大空闲连接数, 0 表示使用默认值"`             // MaxIdleConns 最大空闲连接数
+		ConnMaxLifetime uint64 `json:"conn_max_lifetime" xml:"connMaxLifetime" yaml:"ConnMaxLifetime" comment:"连接最长使用时长(秒), 0 表示不限制"`  // ConnMaxLifetime 连接最长使用时长
+		ConnMaxIdleTime uint64 `json:"conn_max_idle_time" xml:"connMaxIdleTime" yaml:"ConnMaxIdleTime" comment:"连接最长空闲时长(秒), 0 表示不限制"` // ConnMaxIdleTime 连接最长空闲时长
+	}
+	// Replica MySQL只读副本配置，未配置的连接参数与主库相同
+	Replica struct {
+		Host     string `json:"host" xml:"host" yaml:"Host" comment:"连接主机, SQLite 时为数据库文件路径"`                  // Host 连接主机，SQLite 时为副本的数据库文件路径
+		Port     uint16 `json:"port" xml:"port" yaml:"Port" comment:"连接端口, 0 表示与主库相同"`                           // Port 连接端口
+		Username string `json:"username" xml:"username" yaml:"Username" comment:"连接用户名, 为空时与主库相同"`             // Username 连接用户名
+		Password string `json:"password" xml:"password" yaml:"Password" secret:"true" comment:"连接密码, 为空时与主库相同"` // Password 连接密码
+		Weight   int    `json:"weight" xml:"weight" yaml:"Weight" comment:"权重, weighted 策略使用, 0 表示 1"`              // Weight 权重
+	}
+	// Database 命名的数据库连接配置
+	Database struct {
+		Alias string `json:"alias" xml:"alias" yaml:"Alias" comment:"连接名称"`                             // Alias 连接名称，通过 Global.DbNamed 获取连接
+		MySQL MySQL  `json:"mysql" xml:"mysql" yaml:"MySQL" comment:"数据库配置, 未配置的项与默认连接相同"` // MySQL 数据库配置
 	}
 	// MySQL mysql配置
 	MySQL struct {
-		Host     string `json:"host" xml:"host" yaml:"Host" comment:"连接主机"`                                      // Host 连接主机
-		Port     uint16 `json:"port" xml:"port" yaml:"Port" comment:"连接端口"`                                      // Port 连接端口
-		Name     string `json:"name" xml:"name" yaml:"Name" comment:"数据库名称"`                                    // Name 数据库名称
-		Username string `json:"username" xml:"username" yaml:"Username" comment:"连接用户名"`                        // Username 连接用户名
-		Password string `json:"password" xml:"password" yaml:"Password" comment:"连接密码"`                          // Password 连接密码
-		Charset  string `json:"charset" xml:"charset" yaml:"Charset" comment:"连接字符集"`                           // Charset 连接字符集
-		Logger   Logger `json:"logger" xml:"logger" yaml:"Logger" comment:"日志配置 level:[silent error warn info]"` // Logger 日志配置
+		Driver       string    `json:"driver" xml:"driver" yaml:"Driver" comment:"数据库驱动 mysql/postgres/sqlite"`                                // Driver 数据库驱动，sqlite 的数据库名称为文件路径或 :memory:
+		Host         string    `json:"host" xml:"host" yaml:"Host" comment:"连接主机"`                                                              // Host 连接主机
+		Port         uint16    `json:"port" xml:"port" yaml:"Port" comment:"连接端口"`                                                              // Port 连接端口
+		Name         string    `json:"name" xml:"name" yaml:"Name" comment:"数据库名称"`                                                            // Name 数据库名称
+		Username     string    `json:"username" xml:"username" yaml:"Username" comment:"连接用户名"`                                                // Username 连接用户名
+		Password     string    `json:"password" xml:"password" yaml:"Password" secret:"true" comment:"连接密码"`                                    // Password 连接密码
+		Charset      string    `json:"charset" xml:"charset" yaml:"Charset" comment:"连接字符集"`                                                   // Charset 连接字符集
+		Collation    string    `json:"collation" xml:"collation" yaml:"Collation" comment:"连接排序规则, 为空时使用字符集的默认规则"`               // Collation 连接排序规则
+		Timezone     string    `json:"timezone" xml:"timezone" yaml:"Timezone" comment:"解析时间使用的时区, 例如 Local、UTC、Asia/Shanghai"`        // Timezone 解析时间使用的时区
+		Timeout      uint64    `json:"timeout" xml:"timeout" yaml:"Timeout" comment:"建立连接超时(秒), 0 表示使用系统默认值"`                       // Timeout 建立连接超时
+		ReadTimeout  uint64    `json:"read_timeout" xml:"readTimeout" yaml:"ReadTimeout" comment:"读取超时(秒), 0 表示不限制"`                      // ReadTimeout 读取超时
+		WriteTimeout uint64    `json:"write_timeout" xml:"writeTimeout" yaml:"WriteTimeout" comment:"写入超时(秒), 0 表示不限制"`                   // WriteTimeout 写入超时
+		TLS          string    `json:"tls" xml:"tls" yaml:"TLS" comment:"TLS 模式 false/true/skip-verify/preferred, 为空时不使用"`                  // TLS TLS 模式
+		Params       string    `json:"params" xml:"params" yaml:"Params" comment:"其它 DSN 参数, 例如 interpolateParams=true&multiStatements=true"` // Params 其它 DSN 参数
+		Pool         Pool      `json:"pool" xml:"pool" yaml:"Pool" comment:"连接池配置"`                                                            // Pool 连接池配置
+		Logger       Logger    `json:"logger" xml:"logger" yaml:"Logger" comment:"日志配置 level:[silent error warn info]"`                         // Logger 日志配置
+		Retry        Retry     `json:"retry" xml:"retry" yaml:"Retry" comment:"连接重试配置"`                                                       // Retry 连接重试配置
+		Replicas     []Replica `json:"replicas" xml:"replicas" yaml:"Replicas" merge:"key:host" comment:"只读副本"`                                 // Replicas 只读副本
+		Policy       string    `json:"policy" xml:"policy" yaml:"Policy" comment:"副本选择策略 round-robin/weighted"`                               // Policy 副本选择策略
 	}
 )
 
 // Global 结构体包含了应用全局配置，包括服务配置、Redis配置和MySQL数据库配置。
 type Global struct {
-	XMLName xml.Name       `json:"-" xml:"root" yaml:"-"`
-	Service Service        `json:"service" xml:"service" yaml:"Service" comment:"服务配置"` // Service 结构体用于定义服务配置。
-	Redis   Redis          `json:"redis" xml:"redis" yaml:"Redis" comment:"服务配置"`       // Redis 结构体用于定义Redis服务配置。
-	MySQL   MySQL          `json:"mysql" xml:"mysql" yaml:"MySQL" comment:"数据库配置"`     // MySQL 结构体用于定义MySQL数据库配置。
-	event   EventInterface // event 是事件处理的接口，用于解耦事件发布者和订阅者
-	db      *gorm.DB       // db 是一个 *gorm.DB 类型，用于存储GORM数据库会话实例，不暴露给JSON、XML或YAML序列化。
-	rdx     *redis.Client  // rdx 是一个 *redis.Client 类型，用于存储Redis客户端实例，不暴露给JSON、XML或YAML序列化。
+	XMLName   xml.Name          `json:"-" xml:"root" yaml:"-"`
+	Service   Service           `json:"service" xml:"service" yaml:"Service" comment:"服务配置"`                                                        // Service 结构体用于定义服务配置。
+	Redis     Redis             `json:"redis" xml:"redis" yaml:"Redis" comment:"服务配置"`                                                              // Redis 结构体用于定义Redis服务配置。
+	MySQL     MySQL             `json:"mysql" xml:"mysql" yaml:"MySQL" comment:"数据库配置"`                                                            // MySQL 结构体用于定义MySQL数据库配置。
+	Databases []Database        `json:"databases" xml:"databases" yaml:"Databases" merge:"key:alias" comment:"命名数据库连接, 未配置的项与 mysql 相同"` // Databases 命名的数据库连接，MySQL 为默认连接。
+	Modules   Modules           `json:"modules,omitempty" xml:"modules" yaml:"Modules,omitempty" comment:"模块配置"`                                    // Modules 模块配置块，键为模块名称。
+	event     EventInterface    // event 是事件处理的接口，用于解耦事件发布者和订阅者
+	conn      *connections      // conn 共享的数据库和Redis连接注册表，不暴露给JSON、XML或YAML序列化。
+	sources   map[string]string // sources 每个配置项的来源，由 Load 记录
+	secrets   map[string]string // secrets 从 ENC(...) 解密的配置项及其原始加密值，由 Load 记录
 }
 
 // Authorization 函数初始化并返回一个Auth结构体实例。
@@ -125,6 +204,15 @@ func GlobalDefault() Global {
 			Port:        9000,
 			Host:        "127.0.0.1",
 			CrossDomain: true,
+			// 跨域配置包括允许的来源、请求方法、请求头及暴露给浏览器的响应头。
+			Cors: Cors{
+				Origins:     []string{"*"},
+				Methods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"},
+				Headers:     []string{"Refresh-Token", "Accept-Version", "Authorization", "Access-Token", "Language", "Access-Control-Allow-Methods", "Access-Control-Allow-Origin", "Cache-Control", "Content-Type", "if-match", "if-modified-since", "if-none-match", "if-unmodified-since", "X-Requested-With", "Tus-Resumable", "Upload-Length", "Upload-Metadata", "Upload-Offset"},
+				Expose:      []string{"Authorization", "Access-Token", "Refresh-Token", "Refresh-Expires", "Location", "Tus-Resumable", "Tus-Version", "Tus-Extension", "Tus-Max-Size", "Upload-Length", "Upload-Metadata", "Upload-Offset", "Upload-Expires", "Upload-Url"},
+				Credentials: false,
+				MaxAge:      86400,
+			},
 			Log: Logger{
 				// 日志配置包括控制台输出、文件输出路径及日志级别。
 				Console: true,
@@ -143,46 +231,117 @@ func GlobalDefault() Global {
 				Ext:     ".html",
 			},
 			Upload: Upload{
-				Maximum:  50,
-				Resource: Resource{Url: "/upload", Dir: "${DIR}/resources/upload"},
+				Maximum:    50,
+				Resource:   Resource{Url: "/upload", Dir: "${DIR}/resources/upload"},
+				Extensions: []string{".jpg", ".jpeg", ".png", ".gif", ".webp", ".pdf", ".doc", ".docx", ".xls", ".xlsx", ".zip"},
+				Mimes:      []string{"image/*", "application/pdf", "application/zip", "application/octet-stream"},
+				Driver:     "local",
+				S3: S3{
+					Endpoint:  "127.0.0.1:9000",
+					Region:    "us-east-1",
+					Bucket:    "upload",
+					AccessKey: "minioadmin",
+					SecretKey: "minioadmin",
+					Secure:    false,
+					Url:       "",
+					Expire:    3600,
+				},
+				Tus: Tus{
+					Maximum: 4096,
+					Expire:  86400,
+					Dir:     "${DIR}/runtime/tus",
+				},
 			},
+			// 优雅关闭时等待处理中请求完成的最长时间（秒）。
+			Shutdown: 30,
+			Watch:    5,
 		},
 		MySQL: MySQL{
 			// MySQL数据库配置包括主机地址、端口、数据库名、用户名、密码及日志配置。
+			Driver:   DriverMySQL,
 			Host:     "127.0.0.1",
 			Port:     3306,
 			Name:     "dbName",
 			Username: "root",
 			Password: "password",
 			Charset:  "utf8mb4",
+			Timezone: "Local",
+			Timeout:  10,
+			Pool: Pool{
+				// 连接池配置，连接使用和空闲时长的单位为秒。
+				MaxOpenConns:    100,
+				MaxIdleConns:    10,
+				ConnMaxLifetime: 3600,
+				ConnMaxIdleTime: 600,
+			},
 			Logger: Logger{
 				// 数据库操作的日志配置，包括控制台输出、文件输出路径及日志级别。
 				Console: true,
 				File:    "${DIR}/logs/mysql-%Y-%m-%d.log",
 				Level:   "info",
 			},
+			Retry:  Retry{Attempts: 3, Delay: 200, MaxDelay: 5000},
+			Policy: PolicyRoundRobin,
 		},
 		Redis: Redis{
 			// Redis缓存配置包括主机地址、端口、数据库索引、认证密码及TTL（过期时间）。
-			Host: "127.0.0.1",
-			Port: 6379,
-			Db:   0,
-			Auth: "password",
-			TTL:  648000,
+			Host:  "127.0.0.1",
+			Port:  6379,
+			Db:    0,
+			Auth:  "password",
+			TTL:   648000,
+			Retry: Retry{Attempts: 3, Delay: 200, MaxDelay: 5000},
 		},
+		conn: newConnections(),
 	}
 }
 
 // Dialect 方法用于构造并返回MySQL数据库的连接字符串。
+// 时区无法识别时使用本地时区，Params 中的参数追加在最后，与内置参数同名时覆盖内置参数。
 //
 // 返回值:
-// schema: 格式化后的MySQL连接字符串，包含了用户名、密码、主机、端口、数据库名和字符集等信息。
+// schema: 格式化后的MySQL连接字符串，包含了用户名、密码、主机、端口、数据库名、字符集、时区、超时、TLS 和其它参数等信息。
 func (c MySQL) Dialect() (schema string) {
+	config := driver.NewConfig()
+	config.User = c.Username
+	config.Passwd = c.Password
+	config.Net = "tcp"
+	config.Addr = fmt.Sprintf("%v:%v", c.Host, c.Port)
+	config.DBName = c.Name
+	config.Params = map[string]string{"charset": c.Charset}
+	config.Collation = c.Collation
+	config.ParseTime = true
+	config.Loc = time.Local
+	if location, err := time.LoadLocation(c.Timezone); err == nil && c.Timezone != "" {
+		config.Loc = location
+	}
+	config.Timeout = time.Duration(c.Timeout) * time.Second
+	config.ReadTimeout = time.Duration(c.ReadTimeout) * time.Second
+	config.WriteTimeout = time.Duration(c.WriteTimeout) * time.Second
+	config.TLSConfig = c.TLS
+	// 追加其它参数，格式错误的参数由 Validate 报告
+	if params, err := url.ParseQuery(c.Params); err == nil {
+		for name := range params {
+			config.Params[name] = params.Get(name)
+		}
+	}
 	// 构造MySQL连接字符串
-	schema = fmt.Sprintf("%v:%v@tcp(%v:%v)/%v?charset=%v&parseTime=True&loc=Local", c.Username, c.Password, c.Host, c.Port, c.Name, c.Charset)
+	schema = config.FormatDSN()
 	return
 }
 
+// apply 将连接池配置应用到数据库连接池，值为 0 的配置保持 database/sql 的默认行为。
+func (p Pool) apply(db *sql.DB) {
+	if p.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(p.MaxOpenConns)
+	}
+	if p.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(p.MaxIdleConns)
+	}
+	db.SetConnMaxLifetime(time.Duration(p.ConnMaxLifetime) * time.Second)
+	db.SetConnMaxIdleTime(time.Duration(p.ConnMaxIdleTime) * time.Second)
+}
+
 // Log 为指定名称创建一个日志文件。
 //
 // 参数:
@@ -233,7 +392,8 @@ func (g Global) Log(name string, console bool) (write io.Writer, err error) {
 // error: 如果在编码或写入文件过程中遇到错误，将返回一个error；否则返回nil。
 func (g Global) MakeEnv() (err error) {
 	// 使用带注释的编码器对全局配置g进行编码
-	value, err := encoder.NewEncoder(g, encoder.WithComments(encoder.CommentsOnHead)).Encode()
+	// 解密得到的值恢复为原始加密值，避免明文写入文件
+	value, err := encoder.NewEncoder(g.sealed(), encoder.WithComments(encoder.CommentsOnHead)).Encode()
 	if err != nil {
 		return
 	}
@@ -263,12 +423,19 @@ func (g Global) parseDir() Global {
 	}
 	// 展开 Template.Dir 字段中的环境变量
 	g.Service.Template.Dir = os.ExpandEnv(g.Service.Template.Dir)
+	g.Service.Upload.Resource.Dir = os.ExpandEnv(g.Service.Upload.Resource.Dir)
+	g.Service.Upload.Tus.Dir = os.ExpandEnv(g.Service.Upload.Tus.Dir)
 	g.MySQL.Logger.File = os.ExpandEnv(g.MySQL.Logger.File)
+	// 展开命名数据库日志文件中的环境变量
+	for i, database := range g.Databases {
+		database.MySQL.Logger.File = os.ExpandEnv(database.MySQL.Logger.File)
+		g.Databases[i] = database
+	}
 	return g
 }
 
 // LoadEnv 加载环境配置文件
-// 该函数负责从指定路径加载.env.yml文件，并将其内容解析到Global结构体中。
+// 该函数负责从指定路径加载.env.yml文件，并将其内容覆盖到Global结构体中，文件中未出现的配置项保留原值。
 // 如果解析成功，将返回一个指向Global结构体的指针，否则返回nil。
 // 参数：
 //
@@ -278,22 +445,40 @@ func (g Global) parseDir() Global {
 //
 //	Global: 解析成功时返回指向Global结构体的指针，失败时返回nil。
 func (g Global) LoadEnv(event EventInterface) Global {
-	// 从环境变量扩展的路径中读取.env.yml文件的内容
-	value := fsutil.GetContents(os.ExpandEnv("${DIR}/.env.yml"))
-	var global Global
-	// 将读取到的内容解析到global中
-	err := yaml.Unmarshal(value, &global)
+	global, err := g.loadEnv(event)
 	if err != nil {
 		// 解析失败时返回 Global
 		return g
 	}
+	return global
+}
+
+// loadEnv 加载 ${DIR}/.env.yml 并覆盖到配置中，文件不存在时返回原配置，解析失败时返回错误。
+func (g Global) loadEnv(event EventInterface) (Global, error) {
+	file := os.ExpandEnv("${DIR}/.env.yml")
+	value, err := os.ReadFile(file)
+	if err != nil {
+		g.event = event
+		if errors.Is(err, os.ErrNotExist) {
+			return g, nil
+		}
+		return g, err
+	}
+	global := g
+	// 将读取到的内容覆盖到global中，未出现的配置项保留原值
+	err = overlay(&global, func(data interface{}) error {
+		return yaml.Unmarshal(value, data)
+	}, false)
+	if err != nil {
+		return g, fmt.Errorf("%s: %w", file, err)
+	}
 	if event != nil {
 		event.EnvInit(g)
 	}
 	// 解析成功时，进一步处理并返回
 	global = global.parseDir()
 	global.event = event
-	return global
+	return global, nil
 }
 
 // Db 方法用于获取全局数据库连接实例。
@@ -304,16 +489,27 @@ func (g Global) LoadEnv(event EventInterface) Global {
 // db *gorm.DB: 数据库连接实例。
 // err error: 初始化过程中遇到的任何错误。
 func (g Global) Db() (db *gorm.DB, err error) {
+	return g.open(g.MySQL)
+}
+
+// open 根据数据库配置打开数据库连接，连接按照连接参数缓存在共享的注册表中。
+// 建立连接和重试不持有注册表的锁，一个不可用的数据库不会阻塞其它连接的获取和关闭。
+func (g Global) open(config MySQL) (db *gorm.DB, err error) {
+	conn := g.registry()
 	// 检查已有数据库连接，若有则直接返回
-	if g.db != nil {
-		return g.db, nil
+	dsn, key := config.DSN(), config.key()
+	conn.mutex.Lock()
+	db = conn.db[key]
+	conn.mutex.Unlock()
+	if db != nil {
+		return
 	}
 	if g.event != nil {
 		g.event.DbInit()
 	}
 	// 配置日志写入目标，支持文件和控制台输出
 	var write io.Writer
-	write, err = g.Log(g.MySQL.Logger.File, g.MySQL.Logger.Console)
+	write, err = g.Log(config.Logger.File, config.Logger.Console)
 	if err != nil {
 		return
 	}
@@ -324,56 +520,106 @@ func (g Global) Db() (db *gorm.DB, err error) {
 		Colorful:                  false,                            // 不使用彩色日志
 		IgnoreRecordNotFoundError: false,                            // 不忽略记录未找到的错误
 		ParameterizedQueries:      false,                            // 不使用参数化查询日志
-		LogLevel:                  dbLogLevel[g.MySQL.Logger.Level], // 根据配置设定日志级别
+		LogLevel:                  dbLogLevel[config.Logger.Level], // 根据配置设定日志级别
 	})
 	
-	// 初始化gorm数据库连接，配置日志、事务等行为
-	g.db, err = gorm.Open(mysql.Open(g.MySQL.Dialect()), &gorm.Config{
-		SkipDefaultTransaction: true,  // SkipDefaultTransaction 跳过默认事务
-		FullSaveAssociations:   true,  // FullSaveAssociations 在创建或更新时，是否更新关联数据
-		Logger:                 logs,  // Logger 日志接口，用于实现自定义日志
-		DryRun:                 false, // DryRun 生成 SQL 但不执行，可以用于准备或测试生成的 SQL
-		PrepareStmt:            true,  // PrepareStmt 是否禁止创建 prepared statement 并将其缓存
-		AllowGlobalUpdate:      false, // AllowGlobalUpdate 是否允许全局 update/delete
-		QueryFields:            true,  // QueryFields 执行查询时，是否带上所有字段
+	// 初始化gorm数据库连接，配置日志、事务等行为，连接失败时按照重试配置重试
+	err = config.Retry.retry(func() (err error) {
+		db, err = gorm.Open(config.dialector(dsn), &gorm.Config{
+			SkipDefaultTransaction: true,  // SkipDefaultTransaction 跳过默认事务
+			FullSaveAssociations:   true,  // FullSaveAssociations 在创建或更新时，是否更新关联数据
+			Logger:                 logs,  // Logger 日志接口，用于实现自定义日志
+			DryRun:                 false, // DryRun 生成 SQL 但不执行，可以用于准备或测试生成的 SQL
+			PrepareStmt:            true,  // PrepareStmt 是否禁止创建 prepared statement 并将其缓存
+			AllowGlobalUpdate:      false, // AllowGlobalUpdate 是否允许全局 update/delete
+			QueryFields:            true,  // QueryFields 执行查询时，是否带上所有字段
+		})
+		return
 	})
-	return g.db, err
+	if err != nil {
+		return nil, err
+	}
+	// 配置连接池
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	config.Pool.apply(sqlDB)
+	// SQLite 内存数据库的每个连接都是独立的数据库，只能使用一个不过期的连接
+	if config.memory() {
+		sqlDB.SetMaxOpenConns(1)
+		sqlDB.SetMaxIdleConns(1)
+		sqlDB.SetConnMaxLifetime(0)
+		sqlDB.SetConnMaxIdleTime(0)
+	}
+	// 配置只读副本，读取使用副本，写入和事务使用主库
+	if err = config.resolve(db); err != nil {
+		_ = sqlDB.Close()
+		return nil, err
+	}
+	// 其它调用已经缓存了相同的连接时关闭新建的连接，使用已缓存的连接
+	conn.mutex.Lock()
+	defer conn.mutex.Unlock()
+	if cached := conn.db[key]; cached != nil {
+		_ = sqlDB.Close()
+		return cached, nil
+	}
+	conn.db[key] = db
+	return
 }
 
 // Rds 方法用于获取一个全局的 redis 客户端实例。
 // 如果已经存在实例，则直接返回该实例，否则创建一个新的实例。
+// 连接检查和重试不持有注册表的锁，Redis 不可用时不会阻塞数据库连接的获取。
+// 缓存的客户端每隔 recheck 只检查一次连接且不重试，检查失败的调用返回错误，客户端由 go-redis 自行重新连接。
 //
 // 返回值:
 // rdx *redis.Client: redis 客户端实例。
 // err error:         如果在创建或验证连接时出现错误，则返回错误信息；否则为 nil。
 func (g Global) Rds() (rdx *redis.Client, err error) {
-	// 检查是否已经初始化了 redis 客户端，如果是，则直接返回。
-	if g.rdx != nil {
-		return g.rdx, nil
+	conn := g.registry()
+	key := g.Redis.key()
+	// 检查是否已经初始化了 redis 客户端，如果是，则直接返回；超过 recheck 未检查时由一个调用检查连接，
+	// 无论成功与否都记录检查时间，其它调用直接使用缓存的客户端
+	conn.mutex.Lock()
+	cached := conn.rdx[key]
+	stale := cached != nil && time.Since(cached.checked) >= recheck
+	if stale {
+		cached.checked = time.Now()
+	}
+	conn.mutex.Unlock()
+	if cached != nil {
+		// 只检查一次，不在请求中重试；服务恢复后客户端会自行重新建立连接
+		if stale {
+			if err = ping(cached.client); err != nil {
+				return nil, err
+			}
+		}
+		return cached.client, nil
 	}
 	if g.event != nil {
 		g.event.RedisInit()
 	}
 	// 创建一个新的 redis 客户端实例。
-	g.rdx = redis.NewClient(&redis.Options{
+	rdx = redis.NewClient(&redis.Options{
 		DB:       g.Redis.Db,                                       // 数据库索引
 		Addr:     fmt.Sprintf("%s:%d", g.Redis.Host, g.Redis.Port), // 连接地址
 		Password: g.Redis.Auth,                                     // 连接密码
 	})
 	
-	// 尝试与 redis 服务器建立连接并发送"PING"命令。
-	pong, err := g.rdx.Ping().Result()
-	if err != nil {
-		// 如果在发送"PING"命令时出现错误，则直接返回错误。
-		return
+	// 尝试与 redis 服务器建立连接并发送"PING"命令，失败时按照重试配置重试。
+	if err = g.Redis.Retry.retry(func() error { return ping(rdx) }); err != nil {
+		_ = rdx.Close()
+		return nil, err
 	}
 	
-	// 检查"PING"命令的返回结果是否为"PONG"，如果不是，则视为连接错误。
-	if err != nil || !strings.EqualFold(pong, "PONG") {
-		err = errors.New("redis connection error")
-		return
+	// 如果一切正常，则缓存并返回创建的 redis 客户端实例；其它调用已经缓存了客户端时使用已缓存的客户端。
+	conn.mutex.Lock()
+	defer conn.mutex.Unlock()
+	if cached = conn.rdx[key]; cached != nil {
+		_ = rdx.Close()
+		return cached.client, nil
 	}
-	
-	// 如果一切正常，则返回创建的 redis 客户端实例和 nil 错误。
-	return g.rdx, err
+	conn.rdx[key] = &redisClient{client: rdx, checked: time.Now()}
+	return
 }
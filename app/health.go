@@ -0,0 +1,163 @@
+package app
+
+import (
+	`context`
+	`net/http`
+	`time`
+	
+	`github.com/chaodoing/figure/o`
+	`github.com/kataras/iris/v12`
+)
+
+const (
+	StatusUp   = "up"   // StatusUp 依赖可用
+	StatusDown = "down" // StatusDown 依赖不可用
+)
+
+type (
+	// Probe 单个依赖的探测结果
+	Probe struct {
+		Name    string `json:"name" xml:"name" yaml:"Name" comment:"依赖名称"`                        // Name 依赖名称
+		Status  string `json:"status" xml:"status" yaml:"Status" comment:"依赖状态"`                  // Status 依赖状态 up/down
+		Latency string `json:"latency" xml:"latency" yaml:"Latency" comment:"探测耗时"`               // Latency 探测耗时
+		Error   string `json:"error,omitempty" xml:"error,omitempty" yaml:"Error" comment:"错误信息"` // Error 错误信息
+	}
+	
+	// Stats 数据库连接池统计，对应 sql.DBStats
+	Stats struct {
+		MaxOpenConnections int    `json:"max_open_connections" xml:"maxOpenConnections" yaml:"MaxOpenConnections" comment:"最大打开连接数"`               // MaxOpenConnections 最大打开连接数
+		OpenConnections    int    `json:"open_connections" xml:"openConnections" yaml:"OpenConnections" comment:"当前打开连接数"`                         // OpenConnections 当前打开连接数
+		InUse              int    `json:"in_use" xml:"inUse" yaml:"InUse" comment:"使用中的连接数"`                                                       // InUse 使用中的连接数
+		Idle               int    `json:"idle" xml:"idle" yaml:"Idle" comment:"空闲连接数"`                                                               // Idle 空闲连接数
+		WaitCount          int64  `json:"wait_count" xml:"waitCount" yaml:"WaitCount" comment:"等待连接的总次数"`                                         // WaitCount 等待连接的总次数
+		WaitDuration       string `json:"wait_duration" xml:"waitDuration" yaml:"WaitDuration" comment:"等待连接的总时长"`                                // WaitDuration 等待连接的总时长
+		MaxIdleClosed      int64  `json:"max_idle_closed" xml:"maxIdleClosed" yaml:"MaxIdleClosed" comment:"因超过最大空闲连接数关闭的连接数"`            // MaxIdleClosed 因超过最大空闲连接数关闭的连接数
+		MaxIdleTimeClosed  int64  `json:"max_idle_time_closed" xml:"maxIdleTimeClosed" yaml:"MaxIdleTimeClosed" comment:"因超过最长空闲时长关闭的连接数"` // MaxIdleTimeClosed 因超过最长空闲时长关闭的连接数
+		MaxLifetimeClosed  int64  `json:"max_lifetime_closed" xml:"maxLifetimeClosed" yaml:"MaxLifetimeClosed" comment:"因超过最长使用时长关闭的连接数"`  // MaxLifetimeClosed 因超过最长使用时长关闭的连接数
+	}
+	
+	// Health 健康检查结果
+	Health struct {
+		Status string  `json:"status" xml:"status" yaml:"Status" comment:"整体状态"`                                 // Status 整体状态 up/down
+		Probes []Probe `json:"probes" xml:"probes" yaml:"Probes" comment:"依赖状态"`                                 // Probes 各依赖的探测结果
+		Pool   *Stats  `json:"pool,omitempty" xml:"pool,omitempty" yaml:"Pool,omitempty" comment:"数据库连接池统计"` // Pool 数据库连接池统计，数据库不可用时为空
+	}
+)
+
+// probe 执行一次依赖探测并记录耗时。
+func probe(name string, ping func() error) Probe {
+	begin := time.Now()
+	err := ping()
+	value := Probe{Name: name, Status: StatusUp, Latency: time.Since(begin).String()}
+	if err != nil {
+		value.Status = StatusDown
+		value.Error = err.Error()
+	}
+	return value
+}
+
+// Health 探测MySQL、Redis和命名数据库连接的可用性。
+//
+// 返回值:
+// Health: 各依赖的状态与耗时，任一依赖不可用时整体状态为 down。
+func (g Global) Health() (health Health) {
+	health.Status = StatusUp
+	health.Probes = []Probe{
+		probe("mysql", func() error {
+			return g.pingDb(DatabaseDefault)
+		}),
+		probe("redis", func() error {
+			rdx, err := g.Rds()
+			if err != nil {
+				return err
+			}
+			return rdx.Ping().Err()
+		}),
+	}
+	// 命名数据库连接
+	for _, database := range g.Databases {
+		name := database.Alias
+		health.Probes = append(health.Probes, probe("mysql:"+name, func() error {
+			return g.pingDb(name)
+		}))
+	}
+	for _, value := range health.Probes {
+		if value.Status != StatusUp {
+			health.Status = StatusDown
+		}
+	}
+	if health.Probes[0].Status == StatusUp {
+		if stats, err := g.DbStats(); err == nil {
+			health.Pool = &stats
+		}
+	}
+	return
+}
+
+// pingDb 探测指定名称的数据库连接是否可用。
+func (g Global) pingDb(name string) error {
+	db, err := g.DbNamed(name)
+	if err != nil {
+		return err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	return sqlDB.PingContext(ctx)
+}
+
+// DbStats 返回数据库连接池的统计信息，用于监控连接池的使用情况。
+//
+// 返回值:
+// Stats: 连接池统计。
+// error: 获取数据库连接失败时返回错误。
+func (g Global) DbStats() (stats Stats, err error) {
+	db, err := g.Db()
+	if err != nil {
+		return
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return
+	}
+	value := sqlDB.Stats()
+	return Stats{
+		MaxOpenConnections: value.MaxOpenConnections,
+		OpenConnections:    value.OpenConnections,
+		InUse:              value.InUse,
+		Idle:               value.Idle,
+		WaitCount:          value.WaitCount,
+		WaitDuration:       value.WaitDuration.String(),
+		MaxIdleClosed:      value.MaxIdleClosed,
+		MaxIdleTimeClosed:  value.MaxIdleTimeClosed,
+		MaxLifetimeClosed:  value.MaxLifetimeClosed,
+	}, nil
+}
+
+// Health 注册 /healthz 存活检查与 /readyz 就绪检查接口。
+// /healthz 只要进程能够响应即返回 200，并附带各依赖的状态；
+// /readyz 在任一依赖不可用或应用正在优雅关闭时返回 503，配合 Service.ShutdownDelay 让负载均衡在关闭监听之前摘除实例。
+// 探测使用当前生效的配置，重新加载后立即生效。
+// 返回值是 Bootstrap 结构体，允许链式调用。
+func (b Bootstrap) Health() Bootstrap {
+	b.app.Get("/healthz", func(ctx iris.Context) {
+		o.O(ctx, o.Data{Code: 0, Message: "OK", Data: b.Current().Health()})
+	})
+	b.app.Get("/readyz", func(ctx iris.Context) {
+		health := b.Current().Health()
+		if b.state.closing.Load() {
+			health.Status = StatusDown
+			health.Probes = append(health.Probes, Probe{Name: "shutdown", Status: StatusDown, Latency: "0s", Error: "server is shutting down"})
+		}
+		if health.Status != StatusUp {
+			ctx.StatusCode(http.StatusServiceUnavailable)
+			o.O(ctx, o.Data{Code: http.StatusServiceUnavailable, Message: "Service Unavailable", Data: health})
+			return
+		}
+		o.O(ctx, o.Data{Code: 0, Message: "OK", Data: health})
+	})
+	return b
+}
@@ -24,6 +24,23 @@ type EventInterface interface {
 	EnvInit(global Global)
 }
 
+// ReloadEventInterface 在 EventInterface 的基础上增加配置重新加载事件，事件实现该接口时会在配置重新加载后收到通知。
+type ReloadEventInterface interface {
+	EventInterface
+	
+	// Reload 配置重新加载完成。
+	// previous: 重新加载之前的配置。
+	// current: 当前生效的配置。
+	Reload(previous, current Global)
+}
+
+// ValidatorInterface 模型的业务校验，resource.Resource 在绑定请求体之后调用。
+type ValidatorInterface interface {
+	// Validate 校验模型。
+	// 返回值: 校验通过返回 nil，失败返回 error，错误信息作为响应消息。
+	Validate() error
+}
+
 // GlobalInterface 定义了全局接口，包含了数据库、Redis客户端、日志记录器、授权信息的获取方法，以及环境变量的制作和加载方法。
 type GlobalInterface interface {
 	// Db 返回一个初始化好的Gorm数据库实例和可能发生的错误。
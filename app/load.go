@@ -1,37 +1,174 @@
 package app
 
 import (
+	`bufio`
+	`bytes`
 	`encoding/json`
 	`encoding/xml`
+	`errors`
+	`fmt`
 	`os`
+	`path/filepath`
+	`strings`
+	
+	`github.com/chaodoing/figure/toolkit`
+	`gopkg.in/yaml.v2`
+)
+
+const (
+	FormatXML  = "xml"  // FormatXML XML 格式的配置文件
+	FormatJSON = "json" // FormatJSON JSON 格式的配置文件
+	FormatYAML = "yaml" // FormatYAML YAML 格式的配置文件
+	FormatTOML = "toml" // FormatTOML TOML 格式的配置文件，键名与 YAML 相同
 )
 
 // XML 加载配置文件
 func XML(env string, event EventInterface) (data Global, err error) {
-	var content []byte
-	content, err = os.ReadFile(os.ExpandEnv(env))
-	if err != nil {
-		return
-	}
-	err = xml.Unmarshal(content, &data)
-	if err != nil {
-		return
-	}
-	data = data.LoadEnv(event)
-	return
+	return load(env, xml.Unmarshal, event)
 }
 
 // JSON 加载配置文件
 func JSON(env string, event EventInterface) (data Global, err error) {
+	return load(env, json.Unmarshal, event)
+}
+
+// YAML 加载配置文件
+func YAML(env string, event EventInterface) (data Global, err error) {
+	return load(env, yaml.Unmarshal, event)
+}
+
+// TOML 加载配置文件
+func TOML(env string, event EventInterface) (data Global, err error) {
+	return load(env, toolkit.UnmarshalTOML, event)
+}
+
+// load 以默认配置为基础，依次合并配置文件、当前环境的配置文件、.env.yml 和 FIGURE_ 开头的环境变量，解密 ENC(...) 格式的值，并记录每个配置项的来源。
+func load(env string, unmarshal func(content []byte, value interface{}) error, event EventInterface) (data Global, err error) {
 	var content []byte
 	content, err = os.ReadFile(os.ExpandEnv(env))
 	if err != nil {
 		return
 	}
-	err = json.Unmarshal(content, &data)
+	data = GlobalDefault()
+	sources := newSources(data)
+	err = overlay(&data, func(value interface{}) error {
+		return unmarshal(content, value)
+	}, false)
 	if err != nil {
 		return
 	}
-	data = data.LoadEnv(event)
+	sources.mark(data, SourceFile)
+	// 合并当前环境的配置文件，例如 app.development.xml
+	if profile := Profile(env); profile != "" {
+		if content, err = os.ReadFile(os.ExpandEnv(profile)); err == nil {
+			err = overlay(&data, func(value interface{}) error {
+				return unmarshal(content, value)
+			}, true)
+			if err != nil {
+				return data, fmt.Errorf("%s: %w", profile, err)
+			}
+			sources.mark(data, SourceProfile)
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return
+		}
+		err = nil
+	}
+	if data, err = data.loadEnv(event); err != nil {
+		return
+	}
+	sources.mark(data, SourceEnvFile)
+	if data, err = data.environ(os.Environ()); err != nil {
+		return
+	}
+	sources.mark(data, SourceEnv)
+	// 解密 ENC(...) 格式的值，来源记录为加密值所在的层
+	if data, err = data.decrypt(); err != nil {
+		return
+	}
+	data = data.parseDir()
+	data.sources = sources.origin
 	return
 }
+
+// Load 加载配置文件，根据扩展名选择 XML、JSON、YAML 或 TOML，扩展名无法识别时根据文件内容判断。
+//
+// 参数:
+// env string: 配置文件路径，支持环境变量。
+// event EventInterface: 加载环境配置时触发的事件，可以为 nil。
+//
+// 返回值:
+// data Global: 加载并合并当前环境的配置文件、.env.yml 和环境变量后的全局配置。
+// err error: 读取或解析失败时返回错误。
+func Load(env string, event EventInterface) (data Global, err error) {
+	format := Format(env, nil)
+	if format == "" {
+		var content []byte
+		if content, err = os.ReadFile(os.ExpandEnv(env)); err != nil {
+			return
+		}
+		format = Format(env, content)
+	}
+	switch format {
+	case FormatXML:
+		return XML(env, event)
+	case FormatJSON:
+		return JSON(env, event)
+	case FormatYAML:
+		return YAML(env, event)
+	case FormatTOML:
+		return TOML(env, event)
+	default:
+		return data, fmt.Errorf("unsupported config format %q", env)
+	}
+}
+
+// Format 判断配置文件格式。
+// 优先根据扩展名判断；扩展名无法识别且 content 为 nil 时返回空字符串，否则根据内容判断。
+//
+// 参数:
+// file string: 配置文件路径。
+// content []byte: 配置文件内容，可以为 nil。
+//
+// 返回值:
+// string: FormatXML、FormatJSON、FormatYAML、FormatTOML 之一，无法判断时返回空字符串。
+func Format(file string, content []byte) string {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".xml":
+		return FormatXML
+	case ".json":
+		return FormatJSON
+	case ".yml", ".yaml":
+		return FormatYAML
+	case ".toml":
+		return FormatTOML
+	}
+	if content == nil {
+		return ""
+	}
+	content = bytes.TrimSpace(bytes.TrimPrefix(content, []byte("\xef\xbb\xbf")))
+	switch {
+	case len(content) == 0:
+		return ""
+	case content[0] == '<':
+		return FormatXML
+	case content[0] == '{':
+		return FormatJSON
+	}
+	// 根据第一条有效内容区分 TOML 和 YAML：TOML 以表头或 键 = 值 开始
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || line == "---" {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			return FormatTOML
+		}
+		equal, colon := strings.Index(line, "="), strings.Index(line, ":")
+		if equal > 0 && (colon < 0 || equal < colon) {
+			return FormatTOML
+		}
+		return FormatYAML
+	}
+	return ""
+}
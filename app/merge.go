@@ -0,0 +1,145 @@
+package app
+
+import (
+	`fmt`
+	`os`
+	`path/filepath`
+	`reflect`
+	`strings`
+)
+
+const (
+	MergeReplace = "replace" // MergeReplace 切片整体替换，未设置 merge 标签时的默认规则
+	MergeAppend  = "append"  // MergeAppend 追加到已有切片之后
+	mergeKey     = "key:"    // mergeKey 按元素的指定字段合并，例如 merge:"key:url"
+)
+
+// Profile 返回当前环境的配置文件路径，在扩展名之前插入 ENV 环境变量，例如 config/app.xml => config/app.development.xml。
+// ENV 为空时返回空字符串。
+//
+// 参数:
+// file string: 基础配置文件路径。
+//
+// 返回值:
+// string: 环境配置文件路径。
+func Profile(file string) string {
+	env := strings.TrimSpace(os.Getenv("ENV"))
+	if env == "" {
+		return ""
+	}
+	ext := filepath.Ext(file)
+	return strings.TrimSuffix(file, ext) + "." + env + ext
+}
+
+// overlay 将配置内容解码到已有配置上，内容中没有出现的配置项保留原值。
+// merge 为 false 时内容中出现的切片整体替换；为 true 时按照字段的 merge 标签合并切片：
+// replace 整体替换，append 追加到原有元素之后，key:字段 按该字段（json 标签名称）匹配元素，
+// 匹配到的元素深度合并非零值，未匹配到的元素追加到末尾。
+//
+// 参数:
+// global *Global: 被覆盖的配置。
+// decode func(value interface{}) error: 将配置内容解码到 value 的函数。
+// merge bool: 是否按照 merge 标签合并切片。
+//
+// 返回值:
+// error: 解码失败或 merge 标签不正确时返回错误。
+func overlay(global *Global, decode func(value interface{}) error, merge bool) error {
+	// 先解码到零值，找出内容中出现的切片
+	var probe Global
+	if err := decode(&probe); err != nil {
+		return err
+	}
+	// 清空内容中出现的切片，解码后再与原有元素合并
+	var after []func() error
+	var clear func(target, present reflect.Value)
+	clear = func(target, present reflect.Value) {
+		for i := 0; i < target.NumField(); i++ {
+			field := target.Type().Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+			switch value := target.Field(i); value.Kind() {
+			case reflect.Struct:
+				clear(value, present.Field(i))
+			case reflect.Slice:
+				if present.Field(i).IsNil() {
+					continue
+				}
+				rule := MergeReplace
+				if merge && field.Tag.Get("merge") != "" {
+					rule = field.Tag.Get("merge")
+				}
+				original := reflect.ValueOf(value.Interface())
+				value.Set(reflect.Zero(value.Type()))
+				if rule != MergeReplace {
+					after = append(after, func() (err error) {
+						combined, err := combine(rule, original, value)
+						if err == nil {
+							value.Set(combined)
+						}
+						return
+					})
+				}
+			}
+		}
+	}
+	clear(reflect.ValueOf(global).Elem(), reflect.ValueOf(probe))
+	if err := decode(global); err != nil {
+		return err
+	}
+	for _, handle := range after {
+		if err := handle(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// combine 按照合并规则合并原有切片和解码得到的切片，返回新的切片。
+func combine(rule string, original, decoded reflect.Value) (reflect.Value, error) {
+	result := reflect.MakeSlice(original.Type(), original.Len(), original.Len()+decoded.Len())
+	reflect.Copy(result, original)
+	switch {
+	case rule == MergeAppend:
+		return reflect.AppendSlice(result, decoded), nil
+	case strings.HasPrefix(rule, mergeKey) && original.Type().Elem().Kind() == reflect.Struct:
+		index := -1
+		for i := 0; i < original.Type().Elem().NumField(); i++ {
+			if tagName(original.Type().Elem().Field(i)) == strings.TrimPrefix(rule, mergeKey) {
+				index = i
+			}
+		}
+		if index < 0 {
+			return result, fmt.Errorf("merge key %q not found in %s", rule, original.Type().Elem())
+		}
+	items:
+		for i := 0; i < decoded.Len(); i++ {
+			item := decoded.Index(i)
+			for j := 0; j < result.Len(); j++ {
+				if reflect.DeepEqual(result.Index(j).Field(index).Interface(), item.Field(index).Interface()) {
+					deepMerge(result.Index(j), item)
+					continue items
+				}
+			}
+			result = reflect.Append(result, item)
+		}
+		return result, nil
+	default:
+		return result, fmt.Errorf("unsupported merge rule %q for %s", rule, original.Type())
+	}
+}
+
+// deepMerge 将 source 中的非零字段合并到 target，嵌套的结构体逐个字段合并。
+func deepMerge(target, source reflect.Value) {
+	for i := 0; i < target.NumField(); i++ {
+		if target.Type().Field(i).PkgPath != "" {
+			continue
+		}
+		switch field := source.Field(i); {
+		case field.Kind() == reflect.Struct:
+			deepMerge(target.Field(i), field)
+		case !field.IsZero():
+			target.Field(i).Set(field)
+		}
+	}
+}
@@ -0,0 +1,298 @@
+package app
+
+import (
+	`encoding/json`
+	`encoding/xml`
+	`fmt`
+	`sort`
+	
+	`github.com/kataras/iris/v12`
+	`gopkg.in/yaml.v2`
+)
+
+const (
+	formatJSON = "json" // formatJSON JSON 格式的配置块
+	formatXML  = "xml"  // formatXML XML 格式的配置块
+	formatYAML = "yaml" // formatYAML YAML 格式的配置块
+)
+
+type (
+	// Module 可复用的功能模块，例如认证、上传和后台管理，通过 Bootstrap.Use 注册。
+	Module interface {
+		// Name 模块名称，同时作为配置文件 modules 节点下配置块的名称。
+		Name() string
+		// Config 返回模块配置的指针，Bootstrap 会把配置文件中同名的配置块解码到其中；没有配置时返回 nil。
+		Config() interface{}
+		// Depends 返回依赖的模块名称，依赖的模块会先于当前模块注册和迁移。
+		Depends() []string
+		// Register 注册模块的路由、中间件和依赖。
+		Register(app *iris.Application, global Global) error
+		// Migrate 执行模块的数据迁移。
+		Migrate(global Global) error
+		// Shutdown 在应用关闭时释放模块持有的资源。
+		Shutdown(global Global) error
+	}
+	
+	// section 模块配置块，保存从配置文件读取的原始内容或由模块提供的配置值。
+	section struct {
+		format string      // format 原始内容的格式
+		raw    []byte      // raw 原始内容，XML 格式时为元素的内部内容
+		value  interface{} // value 配置值，设置后序列化时优先使用
+		sealed []byte      // sealed 解密 ENC(...) 之前的原始内容，脱敏和写入配置文件时使用
+	}
+	
+	// Modules 模块配置，键为模块名称。
+	Modules map[string]section
+	
+	// innerXML 用于读写 XML 元素的内部内容
+	innerXML struct {
+		Inner []byte `xml:",innerxml"`
+	}
+)
+
+// Set 设置模块的配置值，序列化配置时使用该值。
+//
+// 参数:
+// name string: 模块名称。
+// value interface{}: 配置值，通常为模块配置结构体的指针。
+func (m *Modules) Set(name string, value interface{}) {
+	if *m == nil {
+		*m = make(Modules)
+	}
+	(*m)[name] = section{value: value}
+}
+
+// Decode 将模块的配置块解码到 value 中。
+//
+// 参数:
+// name string: 模块名称。
+// value interface{}: 接收配置的指针。
+//
+// 返回值:
+// ok bool: 配置文件中存在该模块的配置块时返回 true。
+// err error: 解码失败时返回错误。
+func (m Modules) Decode(name string, value interface{}) (ok bool, err error) {
+	data, ok := m[name]
+	if !ok {
+		return
+	}
+	switch data.format {
+	case formatJSON:
+		err = json.Unmarshal(data.raw, value)
+	case formatXML:
+		err = xml.Unmarshal([]byte(fmt.Sprintf("<%s>%s</%s>", name, data.raw, name)), value)
+	case formatYAML:
+		err = yaml.Unmarshal(data.raw, value)
+	default:
+		// 由 Set 设置的配置值，通过 JSON 转换到目标结构体
+		var raw []byte
+		if raw, err = json.Marshal(data.value); err == nil {
+			err = json.Unmarshal(raw, value)
+		}
+	}
+	if err != nil {
+		err = fmt.Errorf("module %s config: %w", name, err)
+	}
+	return
+}
+
+// names 返回排序后的模块名称，保证序列化结果稳定。
+func (m Modules) names() (names []string) {
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return
+}
+
+// clone 复制模块配置，解码时在副本上合并，同名的配置块被替换，避免修改共享同一 map 的其它配置。
+func (m *Modules) clone() Modules {
+	data := make(Modules, len(*m))
+	for name, value := range *m {
+		data[name] = value
+	}
+	return data
+}
+
+// MarshalJSON 实现 json.Marshaler。
+func (m Modules) MarshalJSON() ([]byte, error) {
+	data := make(map[string]json.RawMessage, len(m))
+	for name, value := range m {
+		var err error
+		switch {
+		case value.value != nil:
+			data[name], err = json.Marshal(value.value)
+		case value.format == formatJSON:
+			data[name] = value.raw
+		default:
+			data[name], err = json.Marshal(string(value.raw))
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(data)
+}
+
+// UnmarshalJSON 实现 json.Unmarshaler。
+func (m *Modules) UnmarshalJSON(content []byte) error {
+	var data map[string]json.RawMessage
+	if err := json.Unmarshal(content, &data); err != nil {
+		return err
+	}
+	*m = m.clone()
+	for name, raw := range data {
+		(*m)[name] = section{format: formatJSON, raw: raw}
+	}
+	return nil
+}
+
+// MarshalXML 实现 xml.Marshaler，没有模块配置时不输出节点。
+func (m Modules) MarshalXML(e *xml.Encoder, start xml.StartElement) (err error) {
+	if len(m) == 0 {
+		return
+	}
+	if err = e.EncodeToken(start); err != nil {
+		return
+	}
+	for _, name := range m.names() {
+		value := m[name]
+		element := xml.StartElement{Name: xml.Name{Local: name}}
+		switch {
+		case value.value != nil:
+			err = e.EncodeElement(value.value, element)
+		case value.format == formatXML:
+			err = e.EncodeElement(innerXML{Inner: value.raw}, element)
+		default:
+			err = e.EncodeElement(string(value.raw), element)
+		}
+		if err != nil {
+			return
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+// UnmarshalXML 实现 xml.Unmarshaler，保存每个子元素的内部内容。
+func (m *Modules) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	*m = m.clone()
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch element := token.(type) {
+		case xml.StartElement:
+			var value innerXML
+			if err = d.DecodeElement(&value, &element); err != nil {
+				return err
+			}
+			(*m)[element.Name.Local] = section{format: formatXML, raw: value.Inner}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// MarshalYAML 实现 yaml.Marshaler。
+func (m Modules) MarshalYAML() (interface{}, error) {
+	data := make(map[string]interface{}, len(m))
+	for name, value := range m {
+		switch {
+		case value.value != nil:
+			data[name] = value.value
+		case value.format == formatYAML:
+			var tree interface{}
+			if err := yaml.Unmarshal(value.raw, &tree); err != nil {
+				return nil, err
+			}
+			data[name] = tree
+		default:
+			data[name] = string(value.raw)
+		}
+	}
+	return data, nil
+}
+
+// UnmarshalYAML 实现 yaml.Unmarshaler。
+func (m *Modules) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var data map[string]interface{}
+	if err := unmarshal(&data); err != nil {
+		return err
+	}
+	*m = m.clone()
+	for name, tree := range data {
+		raw, err := yaml.Marshal(tree)
+		if err != nil {
+			return err
+		}
+		(*m)[name] = section{format: formatYAML, raw: raw}
+	}
+	return nil
+}
+
+// WithModules 将模块的默认配置写入全局配置，用于生成包含模块配置块的配置文件。
+//
+// 参数:
+// modules ...Module: 需要写入配置的模块。
+//
+// 返回值:
+// Global: 包含模块配置块的全局配置。
+func (g Global) WithModules(modules ...Module) Global {
+	data := make(Modules, len(g.Modules)+len(modules))
+	for name, value := range g.Modules {
+		data[name] = value
+	}
+	for _, module := range modules {
+		if config := module.Config(); config != nil {
+			data.Set(module.Name(), config)
+		}
+	}
+	g.Modules = data
+	return g
+}
+
+// resolve 按依赖关系对模块排序，被依赖的模块排在前面，没有依赖关系的模块保持注册顺序。
+func resolve(modules []Module) (sorted []Module, err error) {
+	index := make(map[string]Module, len(modules))
+	for _, module := range modules {
+		if _, ok := index[module.Name()]; ok {
+			return nil, fmt.Errorf("module %s registered more than once", module.Name())
+		}
+		index[module.Name()] = module
+	}
+	const (
+		visiting = 1 // visiting 正在访问，再次遇到说明存在循环依赖
+		visited  = 2 // visited 已经加入排序结果
+	)
+	marks := make(map[string]int, len(modules))
+	var visit func(module Module, path []string) error
+	visit = func(module Module, path []string) error {
+		name := module.Name()
+		switch marks[name] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("module dependency cycle: %v", append(path, name))
+		}
+		marks[name] = visiting
+		for _, depend := range module.Depends() {
+			dependency, ok := index[depend]
+			if !ok {
+				return fmt.Errorf("module %s depends on unregistered module %s", name, depend)
+			}
+			if err := visit(dependency, append(path, name)); err != nil {
+				return err
+			}
+		}
+		marks[name] = visited
+		sorted = append(sorted, module)
+		return nil
+	}
+	for _, module := range modules {
+		if err = visit(module, nil); err != nil {
+			return nil, err
+		}
+	}
+	return
+}
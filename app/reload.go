@@ -0,0 +1,190 @@
+package app
+
+import (
+	`fmt`
+	`os`
+	`os/signal`
+	`sort`
+	`strings`
+	`syscall`
+	`time`
+	
+	`github.com/kataras/iris/v12`
+)
+
+// reloadable 可以在运行时重新加载的配置项，其它配置项的修改需要重启应用才能生效
+var reloadable = []string{
+	"service.log.level",
+	"service.cors",
+	"service.shutdown",
+	"service.shutdown_delay",
+	"service.watch",
+	"service.upload.maximum",
+	"service.upload.extensions",
+	"service.upload.mimes",
+	"service.upload.tus.maximum",
+	"service.upload.tus.expire",
+	"redis.ttl",
+}
+
+// Reloadable 判断配置项是否可以在运行时重新加载。
+//
+// 参数:
+// name string: 以 . 分隔的 json 标签路径，例如 service.cors.max_age。
+//
+// 返回值:
+// bool: 可以重新加载时返回 true。
+func Reloadable(name string) bool {
+	for _, prefix := range reloadable {
+		if name == prefix || strings.HasPrefix(name, prefix+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// Reload 将重新加载得到的配置中可以在运行时生效的部分合并到当前配置，数据库和Redis连接等其它内容保持不变。
+//
+// 参数:
+// loaded Global: 重新加载并校验通过的配置。
+//
+// 返回值:
+// current Global: 合并后的配置。
+// ignored []string: 发生了变化但需要重启才能生效的配置项，按名称排序。
+func (g Global) Reload(loaded Global) (current Global, ignored []string) {
+	previous, next := leaves(g), leaves(loaded)
+	for name, value := range next {
+		if old, ok := previous[name]; (!ok || old != value) && !Reloadable(name) {
+			ignored = append(ignored, name)
+		}
+	}
+	for name := range previous {
+		if _, ok := next[name]; !ok && !Reloadable(name) {
+			ignored = append(ignored, name)
+		}
+	}
+	sort.Strings(ignored)
+	current = g
+	current.Service.Log.Level = loaded.Service.Log.Level
+	current.Service.Cors = loaded.Service.Cors
+	current.Service.Shutdown = loaded.Service.Shutdown
+	current.Service.ShutdownDelay = loaded.Service.ShutdownDelay
+	current.Service.Watch = loaded.Service.Watch
+	current.Service.Upload.Maximum = loaded.Service.Upload.Maximum
+	current.Service.Upload.Extensions = loaded.Service.Upload.Extensions
+	current.Service.Upload.Mimes = loaded.Service.Upload.Mimes
+	current.Service.Upload.Tus.Maximum = loaded.Service.Upload.Tus.Maximum
+	current.Service.Upload.Tus.Expire = loaded.Service.Upload.Tus.Expire
+	current.Redis.TTL = loaded.Redis.TTL
+	// 重新加载的配置项使用新的来源
+	if g.sources != nil {
+		current.sources = g.Sources()
+		for name, source := range loaded.sources {
+			if Reloadable(name) {
+				current.sources[name] = source
+			}
+		}
+	}
+	return
+}
+
+// global 返回当前生效的配置。
+func (s *state) global() Global {
+	return *s.current.Load()
+}
+
+// dependency 作为 hero 和 mvc 的动态依赖，每个请求获取当前生效的配置。
+func (s *state) dependency(ctx iris.Context) Global {
+	return s.global()
+}
+
+// stamp 返回配置文件、当前环境的配置文件和 .env.yml 的修改时间与大小，任意一个文件变化时结果随之改变。
+func (s *state) stamp() string {
+	var stamp strings.Builder
+	for _, file := range []string{s.file, Profile(s.file), "${DIR}/.env.yml"} {
+		if file == "" {
+			continue
+		}
+		if info, err := os.Stat(os.ExpandEnv(file)); err == nil {
+			_, _ = fmt.Fprintf(&stamp, "%s:%d:%d;", file, info.ModTime().UnixNano(), info.Size())
+		}
+	}
+	return stamp.String()
+}
+
+// Current 返回当前生效的配置，配置重新加载后与 Bootstrap.Global 不同。
+func (b Bootstrap) Current() Global {
+	return b.state.global()
+}
+
+// OnReload 注册配置重新加载后执行的钩子函数，钩子按注册顺序执行。
+// 返回值是 Bootstrap 结构体，允许链式调用。
+func (b Bootstrap) OnReload(handle func(previous, current Global)) Bootstrap {
+	b.reload = append(b.reload, handle)
+	return b
+}
+
+// Reload 重新加载并校验配置文件，校验通过后原子地替换当前配置中可以在运行时生效的部分。
+// 监听端口等需要重启才能生效的修改会被忽略并记录警告；事件实现了 ReloadEventInterface 时会收到通知，然后依次执行 OnReload 注册的钩子。
+//
+// 返回值:
+// error: 加载或校验失败时返回错误，当前配置保持不变。
+func (b Bootstrap) Reload() (err error) {
+	b.state.mutex.Lock()
+	defer b.state.mutex.Unlock()
+	loaded, err := Load(b.state.file, b.state.event)
+	if err != nil {
+		return
+	}
+	if err = loaded.Validate(); err != nil {
+		return
+	}
+	previous := b.Current()
+	current, ignored := previous.Reload(loaded)
+	for _, name := range ignored {
+		b.app.Logger().Warnf("config %s changed, restart required to take effect", name)
+	}
+	b.state.current.Store(&current)
+	b.app.Logger().SetLevel(current.Service.Log.Level)
+	// 通知订阅者
+	if event, ok := b.state.event.(ReloadEventInterface); ok {
+		event.Reload(previous, current)
+	}
+	for _, handle := range b.reload {
+		handle(previous, current)
+	}
+	return
+}
+
+// watch 每隔 Service.Watch 秒检查配置文件是否变化，变化时或收到 SIGHUP 信号时重新加载配置，done 关闭后停止。
+// Service.Watch 为 0 时只响应 SIGHUP 信号；重新加载失败时记录错误并保留当前配置。
+func (b Bootstrap) watch(done <-chan struct{}) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+	stamp := b.state.stamp()
+	for {
+		var tick <-chan time.Time
+		if interval := b.Current().Service.Watch; interval > 0 {
+			tick = time.After(time.Duration(interval) * time.Second)
+		}
+		select {
+		case <-done:
+			return
+		case <-hangup:
+			b.app.Logger().Info("received SIGHUP, reloading config")
+		case <-tick:
+			if b.state.stamp() == stamp {
+				continue
+			}
+			b.app.Logger().Info("config file changed, reloading config")
+		}
+		stamp = b.state.stamp()
+		if b.state.closing.Load() {
+			return
+		}
+		if err := b.Reload(); err != nil {
+			b.app.Logger().Errorf("reload config: %v", err)
+		}
+	}
+}
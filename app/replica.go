@@ -0,0 +1,175 @@
+package app
+
+import (
+	`context`
+	`database/sql`
+	`strings`
+	`sync`
+	`time`
+	
+	`gorm.io/gorm`
+	`gorm.io/plugin/dbresolver`
+)
+
+const (
+	PolicyRoundRobin = "round-robin" // PolicyRoundRobin 依次使用每个可用的副本
+	PolicyWeighted   = "weighted"    // PolicyWeighted 按照权重平滑地分配到可用的副本
+)
+
+type (
+	// balancer 副本选择策略，实现 dbresolver.Policy。
+	// 所有副本都不可用时读取主库的连接池。
+	balancer struct {
+		policy  string          // policy 选择策略
+		primary gorm.ConnPool   // primary 主库的连接池
+		weights []int           // weights 每个副本的权重
+		mutex   sync.Mutex      // mutex 保护选择和健康状态
+		next    int             // next 轮询的位置
+		current []int           // current 平滑加权轮询的当前权重
+		health  []replicaHealth // health 每个副本的健康状态
+	}
+	
+	// replicaHealth 副本的健康状态
+	replicaHealth struct {
+		down     bool      // down 最近一次检查失败
+		checked  time.Time // checked 最近一次检查的时间
+		checking bool      // checking 是否正在检查
+	}
+)
+
+// ReplicaPolicy 创建健康感知的副本选择策略，用于 dbresolver.Config.Policy。
+// 连接池列表依次对应 replicas；检查失败的副本在下一次检查成功之前不会被选择，所有副本都不可用时返回 primary。
+//
+// 参数:
+// policy string: PolicyRoundRobin 或 PolicyWeighted，其它值按 PolicyRoundRobin 处理。
+// replicas []Replica: 副本配置，PolicyWeighted 使用其中的权重。
+// primary gorm.ConnPool: 主库的连接池，通常为 db.ConnPool，与写入共用同一个连接池。
+//
+// 返回值:
+// dbresolver.Policy: 副本选择策略。
+func ReplicaPolicy(policy string, replicas []Replica, primary gorm.ConnPool) dbresolver.Policy {
+	b := &balancer{
+		policy:  strings.ToLower(policy),
+		primary: primary,
+		weights: make([]int, len(replicas)),
+		current: make([]int, len(replicas)),
+		health:  make([]replicaHealth, len(replicas)),
+	}
+	for i, replica := range replicas {
+		if b.weights[i] = replica.Weight; b.weights[i] <= 0 {
+			b.weights[i] = 1
+		}
+	}
+	return b
+}
+
+// Resolve 实现 dbresolver.Policy，从可用的副本中选择一个连接池。
+func (b *balancer) Resolve(pools []gorm.ConnPool) gorm.ConnPool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	replicas := len(pools)
+	if replicas > len(b.health) {
+		replicas = len(b.health)
+	}
+	var available []int
+	for i := 0; i < replicas; i++ {
+		b.check(i, pools[i])
+		if !b.health[i].down {
+			available = append(available, i)
+		}
+	}
+	if len(available) == 0 {
+		return b.primary
+	}
+	if b.policy == PolicyWeighted {
+		// 平滑加权轮询：每次为所有可用副本增加权重，选择当前权重最大的副本并减去总权重
+		total, selected := 0, available[0]
+		for _, i := range available {
+			b.current[i] += b.weights[i]
+			total += b.weights[i]
+			if b.current[i] > b.current[selected] {
+				selected = i
+			}
+		}
+		b.current[selected] -= total
+		return pools[selected]
+	}
+	b.next = (b.next + 1) % len(available)
+	return pools[available[b.next]]
+}
+
+// check 副本超过 recheck 未检查时在后台检查连接，检查期间沿用上一次的结果。
+func (b *balancer) check(i int, pool gorm.ConnPool) {
+	health := &b.health[i]
+	if health.checking || time.Since(health.checked) < recheck {
+		return
+	}
+	pinger, ok := pool.(interface{ PingContext(ctx context.Context) error })
+	if !ok {
+		return
+	}
+	health.checking = true
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+		err := pinger.PingContext(ctx)
+		b.mutex.Lock()
+		defer b.mutex.Unlock()
+		b.health[i] = replicaHealth{down: err != nil, checked: time.Now()}
+	}()
+}
+
+// Primary 强制查询使用主库，例如写入后立即读取的场景。
+//
+// 参数:
+// db *gorm.DB: 数据库连接。
+//
+// 返回值:
+// *gorm.DB: 使用主库的会话。
+func Primary(db *gorm.DB) *gorm.DB {
+	return db.Clauses(dbresolver.Write)
+}
+
+// replica 返回副本的连接配置，未配置的连接参数与主库相同；SQLite 的副本使用 Host 作为数据库文件路径。
+func (c MySQL) replica(replica Replica) MySQL {
+	if c.driver() == DriverSQLite {
+		c.Name = replica.Host
+		return c
+	}
+	c.Host = replica.Host
+	if replica.Port != 0 {
+		c.Port = replica.Port
+	}
+	if replica.Username != "" {
+		c.Username = replica.Username
+	}
+	if replica.Password != "" {
+		c.Password = replica.Password
+	}
+	return c
+}
+
+// resolve 为数据库连接注册只读副本，没有配置副本时不做任何处理。
+// 副本在首次使用时才建立连接，启动时不可用的副本不会导致失败。
+func (c MySQL) resolve(db *gorm.DB) error {
+	if len(c.Replicas) == 0 {
+		return nil
+	}
+	dialectors := make([]gorm.Dialector, 0, len(c.Replicas))
+	for _, replica := range c.Replicas {
+		dialectors = append(dialectors, c.dialector(c.replica(replica).DSN()))
+	}
+	// 所有副本都不可用时读取主库，复用主库的连接池而不是另外打开一个
+	policy := ReplicaPolicy(c.Policy, c.Replicas, db.Config.ConnPool)
+	db.Config.DisableAutomaticPing = true
+	resolver := dbresolver.Register(dbresolver.Config{Replicas: dialectors, Policy: policy})
+	if err := db.Use(resolver); err != nil {
+		return err
+	}
+	return resolver.Call(func(pool gorm.ConnPool) error {
+		if value, ok := pool.(*sql.DB); ok {
+			c.Pool.apply(value)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,123 @@
+package app
+
+import (
+	`context`
+	`fmt`
+	`io`
+	`io/fs`
+	`net/url`
+	`strings`
+	`time`
+	
+	`github.com/minio/minio-go/v7`
+	`github.com/minio/minio-go/v7/pkg/credentials`
+)
+
+// S3Storage S3 兼容对象存储，可对接 MinIO、AWS S3 等服务。
+type S3Storage struct {
+	config S3            // config 对象存储配置
+	client *minio.Client // client 对象存储客户端
+}
+
+// NewS3Storage 创建 S3 兼容对象存储实例。
+// 创建客户端时不会连接服务，连接错误在首次读写时返回。
+//
+// 参数:
+// config S3: 对象存储配置。
+//
+// 返回值:
+// *S3Storage: 对象存储实例。
+// error: 配置不完整或创建客户端失败时返回错误。
+func NewS3Storage(config S3) (*S3Storage, error) {
+	if config.Endpoint == "" || config.Bucket == "" {
+		return nil, fmt.Errorf("s3 storage requires endpoint and bucket")
+	}
+	client, err := minio.New(config.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(config.AccessKey, config.SecretKey, ""),
+		Secure: config.Secure,
+		Region: config.Region,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &S3Storage{config: config, client: client}, nil
+}
+
+// notExist 将对象不存在的错误转换为 fs.ErrNotExist。
+func (s *S3Storage) notExist(key string, err error) error {
+	switch minio.ToErrorResponse(err).Code {
+	case "NoSuchKey", "NoSuchBucket":
+		return fmt.Errorf("%w: %s", fs.ErrNotExist, key)
+	}
+	return err
+}
+
+// Put 上传对象，size 为 -1 时使用分片上传。
+func (s *S3Storage) Put(key string, reader io.Reader, size int64, mime string) (err error) {
+	_, err = s.client.PutObject(context.Background(), s.config.Bucket, key, reader, size, minio.PutObjectOptions{ContentType: mime})
+	return
+}
+
+// Get 下载对象。
+func (s *S3Storage) Get(key string) (reader io.ReadCloser, err error) {
+	object, err := s.client.GetObject(context.Background(), s.config.Bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, s.notExist(key, err)
+	}
+	// GetObject 不会立即请求服务，通过 Stat 提前暴露对象不存在等错误
+	if _, err = object.Stat(); err != nil {
+		_ = object.Close()
+		return nil, s.notExist(key, err)
+	}
+	return object, nil
+}
+
+// Delete 删除对象，对象不存在时不返回错误。
+func (s *S3Storage) Delete(key string) (err error) {
+	return s.client.RemoveObject(context.Background(), s.config.Bucket, key, minio.RemoveObjectOptions{})
+}
+
+// Stat 获取对象信息。
+func (s *S3Storage) Stat(key string) (object Object, err error) {
+	info, err := s.client.StatObject(context.Background(), s.config.Bucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		return object, s.notExist(key, err)
+	}
+	return Object{Key: info.Key, Size: info.Size, Mime: info.ContentType, Modified: info.LastModified}, nil
+}
+
+// List 递归列出键以 prefix 开头的对象。
+func (s *S3Storage) List(prefix string) (objects []Object, err error) {
+	for info := range s.client.ListObjects(context.Background(), s.config.Bucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if info.Err != nil {
+			return nil, info.Err
+		}
+		objects = append(objects, Object{Key: info.Key, Size: info.Size, Mime: info.ContentType, Modified: info.LastModified})
+	}
+	return
+}
+
+// Url 返回对象的公开访问地址，配置了 Url 时使用配置的地址，否则使用服务地址和存储桶拼接。
+func (s *S3Storage) Url(key string) string {
+	if s.config.Url != "" {
+		return strings.TrimSuffix(s.config.Url, "/") + "/" + strings.TrimPrefix(key, "/")
+	}
+	scheme := "http"
+	if s.config.Secure {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s/%s/%s", scheme, s.config.Endpoint, s.config.Bucket, strings.TrimPrefix(key, "/"))
+}
+
+// Presign 生成预签名下载地址，expire 为 0 时使用配置的 Expire。
+func (s *S3Storage) Presign(key string, expire time.Duration) (value string, err error) {
+	if expire <= 0 {
+		expire = time.Duration(s.config.Expire) * time.Second
+	}
+	var address *url.URL
+	address, err = s.client.PresignedGetObject(context.Background(), s.config.Bucket, key, expire, url.Values{})
+	if err != nil {
+		return
+	}
+	return address.String(), nil
+}
@@ -0,0 +1,361 @@
+package app
+
+import (
+	`encoding/json`
+	`encoding/xml`
+	`fmt`
+	`reflect`
+	`strings`
+)
+
+// SchemaURI 生成的 JSON Schema 使用的规范版本
+const SchemaURI = "https://json-schema.org/draft/2020-12/schema"
+
+// enums 取值有限的配置项，键为以 . 分隔的 json 标签路径
+var enums = map[string][]string{
+	"service.log.level":     logLevels,
+	"service.upload.driver": {"", DriverLocal, DriverS3},
+	"mysql.logger.level":    {`silent`, `error`, `warn`, `info`},
+	"mysql.policy":          {"", PolicyRoundRobin, PolicyWeighted},
+	"mysql.driver":          {"", DriverMySQL, DriverPostgres, DriverSQLite},
+	// 命名数据库连接未配置的项与默认连接相同
+	"databases.mysql.logger.level": {"", `silent`, `error`, `warn`, `info`},
+	"databases.mysql.policy":       {"", PolicyRoundRobin, PolicyWeighted},
+	"databases.mysql.driver":       {"", DriverMySQL, DriverPostgres, DriverSQLite},
+}
+
+type (
+	// jsonSchema JSON Schema 节点
+	jsonSchema struct {
+		Schema               string                 `json:"$schema,omitempty"`              // Schema 规范版本，只出现在根节点
+		Title                string                 `json:"title,omitempty"`                // Title 标题
+		Description          string                 `json:"description,omitempty"`          // Description 说明，来自 comment 标签
+		Type                 string                 `json:"type,omitempty"`                 // Type 类型
+		Properties           map[string]*jsonSchema `json:"properties,omitempty"`           // Properties 对象的属性
+		AdditionalProperties *bool                  `json:"additionalProperties,omitempty"` // AdditionalProperties 是否允许未定义的属性
+		Items                *jsonSchema            `json:"items,omitempty"`                // Items 数组元素
+		Enum                 []string               `json:"enum,omitempty"`                 // Enum 允许的取值
+		Minimum              *uint64                `json:"minimum,omitempty"`              // Minimum 最小值
+		Maximum              *uint64                `json:"maximum,omitempty"`              // Maximum 最大值
+		Default              interface{}            `json:"default,omitempty"`              // Default 默认值
+	}
+	
+	// xsdSchema XSD 根节点
+	xsdSchema struct {
+		XMLName            xml.Name    `xml:"xs:schema"`
+		Namespace          string      `xml:"xmlns:xs,attr"`
+		ElementFormDefault string      `xml:"elementFormDefault,attr"`
+		Element            *xsdElement `xml:"xs:element"`
+	}
+	
+	// xsdElement XSD 元素
+	xsdElement struct {
+		Name          string          `xml:"name,attr"`
+		Type          string          `xml:"type,attr,omitempty"`
+		Default       *string         `xml:"default,attr"`
+		MinOccurs     string          `xml:"minOccurs,attr,omitempty"`
+		MaxOccurs     string          `xml:"maxOccurs,attr,omitempty"`
+		Annotation    *xsdAnnotation  `xml:"xs:annotation"`
+		SimpleType    *xsdSimpleType  `xml:"xs:simpleType"`
+		ComplexType   *xsdComplexType `xml:"xs:complexType"`
+	}
+	
+	// xsdAnnotation XSD 说明
+	xsdAnnotation struct {
+		Documentation string `xml:"xs:documentation"`
+	}
+	
+	// xsdSimpleType XSD 枚举类型
+	xsdSimpleType struct {
+		Restriction struct {
+			Base         string `xml:"base,attr"`
+			Enumerations []struct {
+				Value string `xml:"value,attr"`
+			} `xml:"xs:enumeration"`
+		} `xml:"xs:restriction"`
+	}
+	
+	// xsdComplexType XSD 复合类型，子元素可以按任意顺序出现，与 encoding/xml 的解析方式一致
+	xsdComplexType struct {
+		Choice struct {
+			MinOccurs string        `xml:"minOccurs,attr"`
+			MaxOccurs string        `xml:"maxOccurs,attr"`
+			Elements  []*xsdElement `xml:"xs:element"`
+			Any       *struct {
+				ProcessContents string `xml:"processContents,attr"`
+			} `xml:"xs:any"`
+		} `xml:"xs:choice"`
+	}
+)
+
+// fieldTag 返回字段在指定格式下的名称，忽略的字段返回空字符串；json 使用 tagName，其它格式的规则与 yaml.v2、encoding/xml 一致。
+func fieldTag(field reflect.StructField, key string) string {
+	if key == "json" {
+		return tagName(field)
+	}
+	if field.PkgPath != "" || field.Name == "XMLName" {
+		return ""
+	}
+	name, _, _ := strings.Cut(field.Tag.Get(key), ",")
+	switch {
+	case name == "-":
+		return ""
+	case name != "":
+		return name
+	case key == "yaml":
+		return strings.ToLower(field.Name)
+	default:
+		return field.Name
+	}
+}
+
+// plain 将配置值转换为以指定格式的标签名称为键的普通结构，用作默认值。
+func plain(value reflect.Value, key string) interface{} {
+	switch value.Kind() {
+	case reflect.Struct:
+		data := make(map[string]interface{})
+		for i := 0; i < value.NumField(); i++ {
+			if name := fieldTag(value.Type().Field(i), key); name != "" {
+				data[name] = plain(value.Field(i), key)
+			}
+		}
+		return data
+	case reflect.Slice:
+		items := make([]interface{}, value.Len())
+		for i := range items {
+			items[i] = plain(value.Index(i), key)
+		}
+		return items
+	case reflect.Pointer, reflect.Interface:
+		if value.IsNil() {
+			return nil
+		}
+		return plain(value.Elem(), key)
+	default:
+		return value.Interface()
+	}
+}
+
+// indirect 返回指针或接口指向的值，空指针返回元素类型的零值。
+func indirect(value reflect.Value) reflect.Value {
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			if value.Kind() == reflect.Interface {
+				return value
+			}
+			return reflect.Zero(value.Type().Elem())
+		}
+		value = value.Elem()
+	}
+	return value
+}
+
+// JSONSchema 根据配置结构生成 JSON Schema，说明来自 comment 标签，默认值来自 g，通常为 GlobalDefault().WithModules(...)。
+// 模块配置块按照 WithModules 设置的配置值生成，未知的模块允许出现。
+//
+// 参数:
+// format string: 配置文件格式，FormatJSON 使用 json 标签，FormatYAML 和 FormatTOML 使用 yaml 标签，可用于校验 .env.yml。
+//
+// 返回值:
+// []byte: 缩进后的 JSON Schema。
+// error: 格式不支持时返回错误。
+func (g Global) JSONSchema(format string) ([]byte, error) {
+	var key string
+	switch strings.ToLower(format) {
+	case FormatJSON:
+		key = "json"
+	case FormatYAML, "yml", FormatTOML:
+		key = "yaml"
+	default:
+		return nil, fmt.Errorf("unsupported schema format %q", format)
+	}
+	root := g.jsonSchema("", key, reflect.ValueOf(g))
+	root.Schema = SchemaURI
+	root.Title = "figure configuration"
+	content, err := json.MarshalIndent(root, "", "\t")
+	if err != nil {
+		return nil, err
+	}
+	return append(content, '\n'), nil
+}
+
+// jsonSchema 生成单个配置项的 JSON Schema，path 为以 . 分隔的 json 标签路径。
+func (g Global) jsonSchema(path, key string, value reflect.Value) *jsonSchema {
+	node := new(jsonSchema)
+	if modules, ok := value.Interface().(Modules); ok {
+		allow := true
+		node.Type, node.AdditionalProperties, node.Properties = "object", &allow, make(map[string]*jsonSchema)
+		for _, name := range modules.names() {
+			if modules[name].value != nil {
+				node.Properties[name] = g.jsonSchema(path+"."+name, key, reflect.ValueOf(modules[name].value))
+			}
+		}
+		return node
+	}
+	value = indirect(value)
+	switch value.Kind() {
+	case reflect.Struct:
+		deny := false
+		node.Type, node.AdditionalProperties, node.Properties = "object", &deny, make(map[string]*jsonSchema)
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Type().Field(i)
+			name := fieldTag(field, key)
+			if name == "" {
+				continue
+			}
+			child := g.jsonSchema(strings.TrimPrefix(path+"."+tagName(field), "."), key, value.Field(i))
+			child.Description = field.Tag.Get("comment")
+			node.Properties[name] = child
+		}
+		return node
+	case reflect.Slice:
+		node.Type = "array"
+		node.Items = g.jsonSchema(path, key, reflect.New(value.Type().Elem()).Elem())
+		node.Items.Default = nil
+	case reflect.String:
+		node.Type, node.Enum = "string", enums[path]
+	case reflect.Bool:
+		node.Type = "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		node.Type = "integer"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		minimum := uint64(0)
+		node.Type, node.Minimum = "integer", &minimum
+		if bits := value.Type().Bits(); bits < 64 {
+			maximum := uint64(1)<<bits - 1
+			node.Maximum = &maximum
+		}
+	case reflect.Float32, reflect.Float64:
+		node.Type = "number"
+	default:
+		// 接口和映射等动态内容不限制类型
+		return node
+	}
+	if value.Kind() != reflect.Slice || !value.IsNil() {
+		node.Default = plain(value, key)
+	}
+	return node
+}
+
+// XSD 根据配置结构生成 XML 格式配置文件使用的 XSD，说明来自 comment 标签，默认值来自 g，通常为 GlobalDefault().WithModules(...)。
+// 子元素可以按任意顺序出现；没有通过 WithModules 设置模块配置时，modules 节点允许任意内容。
+//
+// 返回值:
+// []byte: 带有 XML 声明的 XSD。
+// error: 编码失败时返回错误。
+func (g Global) XSD() ([]byte, error) {
+	root := g.xsdElement("", reflect.ValueOf(g))
+	root.Name, root.MinOccurs, root.MaxOccurs = "root", "", ""
+	if field, ok := reflect.TypeOf(g).FieldByName("XMLName"); ok {
+		root.Name, _, _ = strings.Cut(field.Tag.Get("xml"), ",")
+	}
+	content, err := xml.MarshalIndent(xsdSchema{
+		Namespace:          "http://www.w3.org/2001/XMLSchema",
+		ElementFormDefault: "qualified",
+		Element:            root,
+	}, "", "\t")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(content, '\n')...), nil
+}
+
+// xsdElement 生成单个配置项的 XSD 元素，path 为以 . 分隔的 json 标签路径。
+func (g Global) xsdElement(path string, value reflect.Value) *xsdElement {
+	element := new(xsdElement)
+	if modules, ok := value.Interface().(Modules); ok {
+		element.ComplexType = newComplexType()
+		for _, name := range modules.names() {
+			if modules[name].value != nil {
+				child := g.xsdElement(path+"."+name, reflect.ValueOf(modules[name].value))
+				child.Name = name
+				element.ComplexType.Choice.Elements = append(element.ComplexType.Choice.Elements, child)
+			}
+		}
+		if len(element.ComplexType.Choice.Elements) == 0 {
+			element.ComplexType.Choice.Any = &struct {
+				ProcessContents string `xml:"processContents,attr"`
+			}{ProcessContents: "lax"}
+		}
+		return element
+	}
+	value = indirect(value)
+	switch value.Kind() {
+	case reflect.Struct:
+		element.ComplexType = newComplexType()
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Type().Field(i)
+			name := fieldTag(field, "xml")
+			if name == "" {
+				continue
+			}
+			child := g.xsdElement(strings.TrimPrefix(path+"."+tagName(field), "."), value.Field(i))
+			child.Name = name
+			if comment := field.Tag.Get("comment"); comment != "" {
+				child.Annotation = &xsdAnnotation{Documentation: comment}
+			}
+			element.ComplexType.Choice.Elements = append(element.ComplexType.Choice.Elements, child)
+		}
+		return element
+	case reflect.Slice:
+		// 切片的每个元素都是一个同名元素
+		item := g.xsdElement(path, reflect.New(value.Type().Elem()).Elem())
+		item.Default = nil
+		return item
+	}
+	base := xsdType(value.Kind())
+	if values, ok := enums[path]; ok {
+		element.SimpleType = new(xsdSimpleType)
+		element.SimpleType.Restriction.Base = base
+		for _, item := range values {
+			element.SimpleType.Restriction.Enumerations = append(element.SimpleType.Restriction.Enumerations, struct {
+				Value string `xml:"value,attr"`
+			}{Value: item})
+		}
+	} else {
+		element.Type = base
+	}
+	if value.Kind() != reflect.Interface && value.Kind() != reflect.Map {
+		if text := fmt.Sprint(value.Interface()); text != "" {
+			element.Default = &text
+		}
+	}
+	return element
+}
+
+// newComplexType 创建子元素可以按任意顺序、任意次数出现的复合类型。
+func newComplexType() *xsdComplexType {
+	complexType := new(xsdComplexType)
+	complexType.Choice.MinOccurs, complexType.Choice.MaxOccurs = "0", "unbounded"
+	return complexType
+}
+
+// xsdType 返回基本类型对应的 XSD 类型。
+func xsdType(kind reflect.Kind) string {
+	switch kind {
+	case reflect.Bool:
+		return "xs:boolean"
+	case reflect.Int8:
+		return "xs:byte"
+	case reflect.Int16:
+		return "xs:short"
+	case reflect.Int32:
+		return "xs:int"
+	case reflect.Int, reflect.Int64:
+		return "xs:long"
+	case reflect.Uint8:
+		return "xs:unsignedByte"
+	case reflect.Uint16:
+		return "xs:unsignedShort"
+	case reflect.Uint32:
+		return "xs:unsignedInt"
+	case reflect.Uint, reflect.Uint64:
+		return "xs:unsignedLong"
+	case reflect.Float32, reflect.Float64:
+		return "xs:double"
+	case reflect.String:
+		return "xs:string"
+	default:
+		return "xs:anyType"
+	}
+}
@@ -0,0 +1,410 @@
+package app
+
+import (
+	`bytes`
+	`crypto/rand`
+	`encoding/base64`
+	`encoding/json`
+	`encoding/xml`
+	`errors`
+	`fmt`
+	`io`
+	`os`
+	`reflect`
+	`regexp`
+	`strconv`
+	`strings`
+	
+	`github.com/chaodoing/figure/encrypt/aes`
+	`gopkg.in/yaml.v2`
+)
+
+const (
+	MasterKeyEnv     = "FIGURE_MASTER_KEY"      // MasterKeyEnv 保存主密钥的环境变量
+	MasterKeyFileEnv = "FIGURE_MASTER_KEY_FILE" // MasterKeyFileEnv 保存主密钥文件路径的环境变量，默认为 ${DIR}/.master.key
+	Redacted         = "******"                 // Redacted 脱敏后显示的内容
+	encPrefix        = "ENC("                   // encPrefix 加密值的前缀
+	encSuffix        = ")"                      // encSuffix 加密值的后缀
+)
+
+var (
+	// ErrMasterKey 配置中存在加密值但没有找到主密钥
+	ErrMasterKey = errors.New("master key not found, set " + MasterKeyEnv + " or " + MasterKeyFileEnv)
+	// ErrMasterKeyFormat 主密钥不是 Base64 编码的 32 字节随机数
+	ErrMasterKeyFormat = errors.New("master key must be 32 random bytes encoded as base64, generate one with `key generate` or `openssl rand -base64 32`")
+)
+
+// masterKeySize 主密钥的字节数，直接作为 AES-256 密钥使用
+const masterKeySize = 32
+
+// GenerateMasterKey 生成新的主密钥。
+//
+// 返回值:
+// string: Base64 编码的 32 字节随机数。
+// error: 读取随机数失败时返回错误。
+func GenerateMasterKey() (string, error) {
+	key := make([]byte, masterKeySize)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// MasterKey 读取主密钥，优先使用 FIGURE_MASTER_KEY 环境变量，其次读取 FIGURE_MASTER_KEY_FILE 或 ${DIR}/.master.key 文件。
+// 主密钥必须是 Base64 编码的 32 字节随机数，由 GenerateMasterKey 生成，不接受口令。
+//
+// 返回值:
+// key string: 主密钥，首尾空白会被去除。
+// err error: 没有配置主密钥、密钥文件读取失败或格式不正确时返回错误。
+func MasterKey() (key string, err error) {
+	if key, err = readMasterKey(); err != nil {
+		return
+	}
+	_, err = masterKey(key)
+	return
+}
+
+// readMasterKey 从环境变量或密钥文件读取主密钥，不校验格式。
+func readMasterKey() (key string, err error) {
+	if key = strings.TrimSpace(os.Getenv(MasterKeyEnv)); key != "" {
+		return
+	}
+	file := os.Getenv(MasterKeyFileEnv)
+	if file == "" {
+		file = "${DIR}/.master.key"
+	}
+	content, err := os.ReadFile(os.ExpandEnv(file))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", ErrMasterKey
+	}
+	if err != nil {
+		return
+	}
+	if key = strings.TrimSpace(string(content)); key == "" {
+		return "", ErrMasterKey
+	}
+	return
+}
+
+// Encrypted 判断配置值是否为 ENC(...) 格式的加密值。
+func Encrypted(value string) bool {
+	return strings.HasPrefix(value, encPrefix) && strings.HasSuffix(value, encSuffix)
+}
+
+// Encrypt 使用主密钥加密配置值，返回可以直接写入配置文件的 ENC(...) 格式。
+// 使用 AES-256-GCM 加密，解码后的主密钥直接作为密钥，每次加密使用随机的 nonce，相同的明文得到不同的密文。
+//
+// 参数:
+// value string: 明文。
+// key string: Base64 编码的 32 字节主密钥。
+//
+// 返回值:
+// string: ENC(密文) 格式的加密值。
+// error: 主密钥格式不正确时返回 ErrMasterKeyFormat，读取随机数失败时返回错误。
+func Encrypt(value, key string) (string, error) {
+	secret, err := masterKey(key)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := aes.EncryptGCM(value, secret)
+	if err != nil {
+		return "", err
+	}
+	return encPrefix + sealed + encSuffix, nil
+}
+
+// Decrypt 使用主密钥解密 ENC(...) 格式的配置值，不是加密值时原样返回。
+//
+// 参数:
+// value string: 配置值。
+// key string: Base64 编码的 32 字节主密钥。
+//
+// 返回值:
+// string: 明文。
+// error: 主密钥或密文格式不正确时返回错误，密钥不正确或密文被篡改时返回 aes.ErrAuthentication。
+func Decrypt(value, key string) (string, error) {
+	if !Encrypted(value) {
+		return value, nil
+	}
+	secret, err := masterKey(key)
+	if err != nil {
+		return "", err
+	}
+	return aes.DecryptGCM(strings.TrimSuffix(strings.TrimPrefix(value, encPrefix), encSuffix), secret)
+}
+
+// masterKey 解码主密钥，返回 AES-256 密钥，不是 Base64 编码的 32 字节时返回 ErrMasterKeyFormat。
+func masterKey(key string) (string, error) {
+	secret, err := base64.StdEncoding.DecodeString(key)
+	if err != nil || len(secret) != masterKeySize {
+		return "", ErrMasterKeyFormat
+	}
+	return string(secret), nil
+}
+
+// walkStrings 遍历配置中的所有字符串字段、字符串切片元素和 map 的值，path 为以 . 分隔的 json 标签路径，map 使用键作为路径。
+// 切片和 map 会被复制后再修改，避免影响共享底层数组的其它配置副本。
+func walkStrings(prefix string, field reflect.StructField, value reflect.Value, handle func(path string, field reflect.StructField, value reflect.Value) error) error {
+	switch value.Kind() {
+	case reflect.Struct:
+		for i := 0; i < value.NumField(); i++ {
+			child := value.Type().Field(i)
+			if name := tagName(child); name != "" {
+				if err := walkStrings(strings.TrimPrefix(prefix+"."+name, "."), child, value.Field(i), handle); err != nil {
+					return err
+				}
+			}
+		}
+	case reflect.Slice:
+		items := reflect.MakeSlice(value.Type(), value.Len(), value.Len())
+		reflect.Copy(items, value)
+		for i := 0; i < items.Len(); i++ {
+			if err := walkStrings(prefix+"."+strconv.Itoa(i), field, items.Index(i), handle); err != nil {
+				return err
+			}
+		}
+		if !value.IsNil() {
+			value.Set(items)
+		}
+	case reflect.Map:
+		if value.IsNil() {
+			return nil
+		}
+		items := reflect.MakeMapWithSize(value.Type(), value.Len())
+		iter := value.MapRange()
+		for iter.Next() {
+			// map 的值不可寻址，复制后再遍历
+			item := reflect.New(value.Type().Elem()).Elem()
+			item.Set(iter.Value())
+			if err := walkStrings(prefix+"."+fmt.Sprint(iter.Key().Interface()), field, item, handle); err != nil {
+				return err
+			}
+			items.SetMapIndex(iter.Key(), item)
+		}
+		value.Set(items)
+	case reflect.String:
+		return handle(prefix, field, value)
+	}
+	return nil
+}
+
+// decrypt 解密配置和模块配置块中所有 ENC(...) 格式的值，并记录原始的加密值，只有存在加密值时才需要主密钥。
+func (g Global) decrypt() (Global, error) {
+	var key string
+	open := func(value string) (text string, err error) {
+		if key == "" {
+			if key, err = MasterKey(); err != nil {
+				return
+			}
+		}
+		return Decrypt(value, key)
+	}
+	secrets := make(map[string]string)
+	err := walkStrings("", reflect.StructField{}, reflect.ValueOf(&g).Elem(), func(path string, field reflect.StructField, value reflect.Value) (err error) {
+		if !Encrypted(value.String()) {
+			return
+		}
+		text, err := open(value.String())
+		if err != nil {
+			return fmt.Errorf("%s: decrypt: %w", path, err)
+		}
+		secrets[path] = value.String()
+		value.SetString(text)
+		return
+	})
+	g.secrets = secrets
+	if err != nil {
+		return g, err
+	}
+	g.Modules, err = g.Modules.decrypt(open)
+	return g, err
+}
+
+// Redact 返回脱敏后的配置副本，用于输出或展示有效配置。
+// 从 ENC(...) 解密得到的值恢复为原始的加密值，带有 secret:"true" 标签的其它非空字段替换为 Redacted；
+// 模块配置块恢复为解密前的原始内容，由 Modules.Set 设置的配置值同样按照 secret 标签脱敏。
+func (g Global) Redact() Global {
+	_ = walkStrings("", reflect.StructField{}, reflect.ValueOf(&g).Elem(), func(path string, field reflect.StructField, value reflect.Value) error {
+		if sealed, ok := g.secrets[path]; ok {
+			value.SetString(sealed)
+		} else if field.Tag.Get("secret") == "true" && value.String() != "" {
+			value.SetString(Redacted)
+		}
+		return nil
+	})
+	g.Modules = g.Modules.redact()
+	return g
+}
+
+// sealed 返回将解密得到的值恢复为原始加密值的配置副本，写入配置文件时避免泄露明文。
+func (g Global) sealed() Global {
+	g.Modules = g.Modules.sealed()
+	if len(g.secrets) == 0 {
+		return g
+	}
+	_ = walkStrings("", reflect.StructField{}, reflect.ValueOf(&g).Elem(), func(path string, field reflect.StructField, value reflect.Value) error {
+		if sealed, ok := g.secrets[path]; ok {
+			value.SetString(sealed)
+		}
+		return nil
+	})
+	return g
+}
+
+// decrypt 解密模块配置块中的 ENC(...) 值，解密前的原始内容保存在 sealed 中，没有加密值时返回原配置。
+func (m Modules) decrypt(open func(value string) (string, error)) (Modules, error) {
+	var data Modules
+	for _, name := range m.names() {
+		value, err := m[name].decrypt(open)
+		if err != nil {
+			return m, fmt.Errorf("modules.%s: decrypt: %w", name, err)
+		}
+		if value.sealed == nil {
+			continue
+		}
+		if data == nil {
+			data = m.clone()
+		}
+		data[name] = value
+	}
+	if data == nil {
+		return m, nil
+	}
+	return data, nil
+}
+
+// sealed 返回将配置块恢复为解密前原始内容的副本。
+func (m Modules) sealed() Modules {
+	var data Modules
+	for name, value := range m {
+		if value.sealed == nil {
+			continue
+		}
+		if data == nil {
+			data = m.clone()
+		}
+		data[name] = section{format: value.format, raw: value.sealed}
+	}
+	if data == nil {
+		return m
+	}
+	return data
+}
+
+// redact 返回脱敏后的模块配置副本，原始内容恢复为解密前的内容，配置值中带有 secret:"true" 标签的非空字段替换为 Redacted。
+func (m Modules) redact() Modules {
+	if len(m) == 0 {
+		return m
+	}
+	data := m.sealed()
+	data = data.clone()
+	for name, value := range data {
+		if value.value == nil {
+			continue
+		}
+		item := reflect.ValueOf(value.value)
+		pointer := item.Kind() == reflect.Pointer
+		if pointer {
+			if item.IsNil() {
+				continue
+			}
+			item = item.Elem()
+		}
+		// 在副本上脱敏，不修改模块持有的配置
+		copied := reflect.New(item.Type()).Elem()
+		copied.Set(item)
+		_ = walkStrings("modules."+name, reflect.StructField{}, copied, func(path string, field reflect.StructField, value reflect.Value) error {
+			if field.Tag.Get("secret") == "true" && value.String() != "" {
+				value.SetString(Redacted)
+			}
+			return nil
+		})
+		if pointer {
+			value.value = copied.Addr().Interface()
+		} else {
+			value.value = copied.Interface()
+		}
+		data[name] = value
+	}
+	return data
+}
+
+// decrypt 解密配置块中的 ENC(...) 值。JSON 和 YAML 格式解析后逐个解密字符串，XML 格式直接替换内容中的加密值并转义；
+// 由 Modules.Set 设置的配置值来自代码，不需要解密。
+func (s section) decrypt(open func(value string) (string, error)) (value section, err error) {
+	var changed bool
+	decrypt := func(sealed string) (string, error) {
+		changed = true
+		return open(sealed)
+	}
+	value = s
+	switch s.format {
+	case formatJSON:
+		var tree interface{}
+		decoder := json.NewDecoder(bytes.NewReader(s.raw))
+		decoder.UseNumber()
+		if err = decoder.Decode(&tree); err != nil {
+			return
+		}
+		if tree, err = decryptTree(tree, decrypt); err != nil || !changed {
+			return
+		}
+		value.raw, err = json.Marshal(tree)
+	case formatYAML:
+		var tree interface{}
+		if err = yaml.Unmarshal(s.raw, &tree); err != nil {
+			return
+		}
+		if tree, err = decryptTree(tree, decrypt); err != nil || !changed {
+			return
+		}
+		value.raw, err = yaml.Marshal(tree)
+	case formatXML:
+		value.raw = encToken.ReplaceAllFunc(s.raw, func(token []byte) []byte {
+			text, failed := decrypt(string(token))
+			if failed != nil {
+				err = failed
+				return token
+			}
+			var escaped bytes.Buffer
+			_ = xml.EscapeText(&escaped, []byte(text))
+			return escaped.Bytes()
+		})
+	}
+	if err == nil && changed {
+		value.sealed = s.raw
+	}
+	return
+}
+
+// encToken 匹配 XML 配置块中的 ENC(...) 加密值
+var encToken = regexp.MustCompile(`ENC\([A-Za-z0-9+/=]*\)`)
+
+// decryptTree 解密 JSON 或 YAML 解析得到的配置树中的 ENC(...) 字符串，map 和切片在原处修改。
+func decryptTree(tree interface{}, decrypt func(value string) (string, error)) (_ interface{}, err error) {
+	switch value := tree.(type) {
+	case string:
+		if Encrypted(value) {
+			return decrypt(value)
+		}
+	case map[string]interface{}:
+		for name, item := range value {
+			if value[name], err = decryptTree(item, decrypt); err != nil {
+				return
+			}
+		}
+	case map[interface{}]interface{}:
+		for name, item := range value {
+			if value[name], err = decryptTree(item, decrypt); err != nil {
+				return
+			}
+		}
+	case []interface{}:
+		for i, item := range value {
+			if value[i], err = decryptTree(item, decrypt); err != nil {
+				return
+			}
+		}
+	}
+	return tree, nil
+}
@@ -0,0 +1,250 @@
+package app
+
+import (
+	`errors`
+	`fmt`
+	`io`
+	`io/fs`
+	`mime`
+	`os`
+	`path`
+	`path/filepath`
+	`reflect`
+	`strings`
+	`sync`
+	`time`
+	
+	`github.com/gookit/goutil/fsutil`
+)
+
+const (
+	DriverLocal = "local" // DriverLocal 本地磁盘存储
+	DriverS3    = "s3"    // DriverS3 S3 兼容对象存储
+)
+
+type (
+	// Object 存储对象信息
+	Object struct {
+		Key      string    `json:"key" xml:"key" yaml:"Key" comment:"对象键"`                  // Key 对象键，使用 / 分隔
+		Size     int64     `json:"size" xml:"size" yaml:"Size" comment:"对象大小"`             // Size 对象大小，单位字节
+		Mime     string    `json:"mime" xml:"mime" yaml:"Mime" comment:"对象类型"`             // Mime 对象类型
+		Modified time.Time `json:"modified" xml:"modified" yaml:"Modified" comment:"修改时间"` // Modified 最后修改时间
+	}
+	
+	// Storage 文件存储接口，上传的文件通过该接口写入本地磁盘或对象存储。
+	Storage interface {
+		// Put 写入对象，size 未知时传 -1。
+		Put(key string, reader io.Reader, size int64, mime string) (err error)
+		// Get 读取对象内容，调用方负责关闭返回的 reader。对象不存在时返回的错误满足 errors.Is(err, fs.ErrNotExist)。
+		Get(key string) (reader io.ReadCloser, err error)
+		// Delete 删除对象。
+		Delete(key string) (err error)
+		// Stat 获取对象信息。对象不存在时返回的错误满足 errors.Is(err, fs.ErrNotExist)。
+		Stat(key string) (object Object, err error)
+		// List 列出键以 prefix 开头的所有对象。
+		List(prefix string) (objects []Object, err error)
+		// Url 返回对象的公开访问地址。
+		Url(key string) string
+		// Presign 返回对象在 expire 时间内有效的临时访问地址。
+		Presign(key string, expire time.Duration) (url string, err error)
+	}
+	
+	// storages 按照上传配置缓存存储实例，配置重新加载后重新创建
+	storages struct {
+		mutex   sync.Mutex // mutex 保护缓存的存储实例
+		upload  Upload     // upload 创建存储实例时的上传配置
+		storage Storage    // storage 缓存的存储实例
+	}
+	
+	// LocalStorage 本地磁盘存储，文件保存在 Resource.Dir 目录下并通过 Resource.Url 映射的静态资源路径访问。
+	LocalStorage struct {
+		Resource Resource // Resource 存储目录与访问路径
+	}
+)
+
+// NewStorage 根据上传配置创建存储实例。
+//
+// 参数:
+// upload Upload: 上传配置，Driver 为空或 local 时使用本地磁盘，s3 时使用 S3 兼容对象存储。
+//
+// 返回值:
+// Storage: 存储实例。
+// error: 驱动不支持或创建客户端失败时返回错误。
+func NewStorage(upload Upload) (Storage, error) {
+	switch {
+	case upload.Local():
+		return LocalStorage{Resource: Resource{Url: upload.Resource.Url, Dir: os.ExpandEnv(upload.Resource.Dir)}}, nil
+	case strings.EqualFold(upload.Driver, DriverS3):
+		return NewS3Storage(upload.S3)
+	default:
+		return nil, fmt.Errorf("unsupported storage driver %q", upload.Driver)
+	}
+}
+
+// get 返回上传配置对应的存储实例，配置与缓存的实例相同时直接返回。
+func (s *storages) get(upload Upload) (Storage, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.storage != nil && reflect.DeepEqual(s.upload, upload) {
+		return s.storage, nil
+	}
+	storage, err := NewStorage(upload)
+	if err != nil {
+		return nil, err
+	}
+	s.upload, s.storage = upload, storage
+	return storage, nil
+}
+
+// Local 判断上传文件是否保存在本地磁盘。
+func (u Upload) Local() bool {
+	return u.Driver == "" || strings.EqualFold(u.Driver, DriverLocal)
+}
+
+// mapped 判断访问路径是否已经在静态资源中配置。
+func (s Service) mapped(url string) bool {
+	for _, resource := range s.Resources {
+		if path.Clean(resource.Url) == path.Clean(url) {
+			return true
+		}
+	}
+	return false
+}
+
+// Storage 根据 Service.Upload 配置创建文件存储实例。
+func (g Global) Storage() (Storage, error) {
+	return NewStorage(g.Service.Upload)
+}
+
+// file 将对象键转换为本地文件路径，拒绝跳出存储目录的键。
+func (l LocalStorage) file(key string) (name string, err error) {
+	key = path.Clean("/" + key)
+	if key == "/" {
+		return "", fmt.Errorf("invalid storage key %q", key)
+	}
+	return filepath.Join(l.Resource.Dir, filepath.FromSlash(key)), nil
+}
+
+// Put 将内容先写入同目录的临时文件，再重命名为目标文件，避免读取到写了一半的文件。
+func (l LocalStorage) Put(key string, reader io.Reader, size int64, mime string) (err error) {
+	name, err := l.file(key)
+	if err != nil {
+		return
+	}
+	if err = fsutil.Mkdir(filepath.Dir(name), 0755); err != nil {
+		return
+	}
+	temp, err := os.CreateTemp(filepath.Dir(name), ".storage-*")
+	if err != nil {
+		return
+	}
+	defer os.Remove(temp.Name())
+	_, err = io.Copy(temp, reader)
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return
+	}
+	if err = os.Chmod(temp.Name(), 0644); err != nil {
+		return
+	}
+	return os.Rename(temp.Name(), name)
+}
+
+// Get 打开本地文件。
+func (l LocalStorage) Get(key string) (reader io.ReadCloser, err error) {
+	name, err := l.file(key)
+	if err != nil {
+		return
+	}
+	return os.Open(name)
+}
+
+// Delete 删除本地文件，文件不存在时不返回错误。
+func (l LocalStorage) Delete(key string) (err error) {
+	name, err := l.file(key)
+	if err != nil {
+		return
+	}
+	if err = os.Remove(name); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return
+}
+
+// Stat 获取本地文件信息，类型根据扩展名推断。
+func (l LocalStorage) Stat(key string) (object Object, err error) {
+	name, err := l.file(key)
+	if err != nil {
+		return
+	}
+	info, err := os.Stat(name)
+	if err != nil {
+		return
+	}
+	if info.IsDir() {
+		return object, fmt.Errorf("%w: %s is a directory", fs.ErrNotExist, key)
+	}
+	return Object{
+		Key:      strings.TrimPrefix(path.Clean("/"+key), "/"),
+		Size:     info.Size(),
+		Mime:     mime.TypeByExtension(filepath.Ext(name)),
+		Modified: info.ModTime(),
+	}, nil
+}
+
+// List 遍历存储目录，返回键以 prefix 开头的文件，忽略以 . 开头的临时文件和内部目录。
+func (l LocalStorage) List(prefix string) (objects []Object, err error) {
+	if !fsutil.PathExists(l.Resource.Dir) {
+		return
+	}
+	err = filepath.WalkDir(l.Resource.Dir, func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		// 跳过临时文件和断点续传等内部目录
+		if strings.HasPrefix(entry.Name(), ".") && name != l.Resource.Dir {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(l.Resource.Dir, name)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		objects = append(objects, Object{
+			Key:      key,
+			Size:     info.Size(),
+			Mime:     mime.TypeByExtension(filepath.Ext(name)),
+			Modified: info.ModTime(),
+		})
+		return nil
+	})
+	return
+}
+
+// Url 返回文件在静态资源映射下的访问路径。
+func (l LocalStorage) Url(key string) string {
+	return path.Join(l.Resource.Url, path.Clean("/"+key))
+}
+
+// Presign 本地文件通过静态资源映射公开访问，直接返回访问路径。
+func (l LocalStorage) Presign(key string, expire time.Duration) (url string, err error) {
+	if _, err = l.Stat(key); err != nil {
+		return
+	}
+	return l.Url(key), nil
+}
@@ -0,0 +1,379 @@
+package app
+
+import (
+	`encoding/base64`
+	`errors`
+	`fmt`
+	`io`
+	`net/http`
+	`os`
+	`path`
+	`path/filepath`
+	`strconv`
+	`strings`
+	`time`
+	
+	`github.com/chaodoing/figure/encrypt`
+	`github.com/go-redis/redis`
+	`github.com/gookit/goutil/fsutil`
+	`github.com/kataras/iris/v12`
+)
+
+const (
+	TusVersion   = "1.0.0"                           // TusVersion 支持的 tus 协议版本
+	TusExtension = "creation,expiration,termination" // TusExtension 支持的 tus 协议扩展
+	tusPrefix    = "tus:"                            // tusPrefix 上传状态在 Redis 中的键前缀
+	tusOffset    = "application/offset+octet-stream" // tusOffset PATCH 请求要求的内容类型
+	tusLock      = 30 * time.Second                  // tusLock PATCH 锁的有效期，写入期间定期续期，客户端崩溃后很快释放
+)
+
+var (
+	// tusRefresh 锁仍属于当前请求时续期
+	tusRefresh = redis.NewScript(`if redis.call("GET", KEYS[1]) == ARGV[1] then return redis.call("PEXPIRE", KEYS[1], ARGV[2]) end return 0`)
+	// tusRelease 锁仍属于当前请求时释放，避免删除过期后被其它请求获取的锁
+	tusRelease = redis.NewScript(`if redis.call("GET", KEYS[1]) == ARGV[1] then return redis.call("DEL", KEYS[1]) end return 0`)
+)
+
+// tus 基于 tus 1.0.0 协议的断点续传处理器，上传状态保存在 Redis 中。
+type tus struct {
+	route   string        // route 挂载的路由
+	upload  Upload        // upload 上传配置
+	storage Storage       // storage 上传完成后写入的存储
+	rdx     *redis.Client // rdx 保存上传状态的 Redis 客户端，每个请求从连接注册表获取
+}
+
+// key 返回上传状态在 Redis 中的键。
+func (t tus) key(id string) string {
+	return tusPrefix + id
+}
+
+// file 返回未完成文件的本地路径，位于 Upload.Tus.Dir 暂存目录中。
+func (t tus) file(id string) string {
+	return filepath.Join(os.ExpandEnv(t.upload.Tus.Dir), id)
+}
+
+// expire 返回未完成上传的保留时长。
+func (t tus) expire() time.Duration {
+	return time.Duration(t.upload.Tus.Expire) * time.Second
+}
+
+// metadata 解析 Upload-Metadata 请求头，格式为逗号分隔的 "键 base64值"。
+func (t tus) metadata(value string) map[string]string {
+	data := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		fields := strings.Fields(pair)
+		if len(fields) == 0 {
+			continue
+		}
+		data[fields[0]] = ""
+		if len(fields) > 1 {
+			if decoded, err := base64.StdEncoding.DecodeString(fields[1]); err == nil {
+				data[fields[0]] = string(decoded)
+			}
+		}
+	}
+	return data
+}
+
+// state 读取上传状态，上传不存在或已过期时返回 false。
+func (t tus) state(id string) (state map[string]string, ok bool) {
+	state, err := t.rdx.HGetAll(t.key(id)).Result()
+	if err != nil || len(state) == 0 {
+		return nil, false
+	}
+	return state, true
+}
+
+// lock 获取上传的 PATCH 锁，锁的有效期为 tusLock，获取成功后在返回的 unlock 调用之前定期续期。
+// 锁被其它请求持有时 ok 为 false，Redis 不可用时返回错误。
+func (t tus) lock(id string) (unlock func(), ok bool, err error) {
+	key, token := t.key(id)+":lock", encrypt.UUID()
+	if ok, err = t.rdx.SetNX(key, token, tusLock).Result(); err != nil || !ok {
+		return
+	}
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(tusLock / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				tusRefresh.Run(t.rdx, []string{key}, token, tusLock.Milliseconds())
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		tusRelease.Run(t.rdx, []string{key}, token)
+	}, true, nil
+}
+
+// stop 以指定状态码结束请求。
+func (t tus) stop(ctx iris.Context, code int, err error) {
+	if err != nil && code >= http.StatusInternalServerError {
+		ctx.Application().Logger().Error(err)
+	}
+	ctx.StopWithStatus(code)
+}
+
+// resumable 为响应设置协议版本头，并校验请求的协议版本。
+func (t tus) resumable(ctx iris.Context) {
+	ctx.Header("Tus-Resumable", TusVersion)
+	ctx.Header("Cache-Control", "no-store")
+	if ctx.Method() != http.MethodOptions && ctx.GetHeader("Tus-Resumable") != TusVersion {
+		ctx.Header("Tus-Version", TusVersion)
+		ctx.StopWithStatus(http.StatusPreconditionFailed)
+		return
+	}
+	ctx.Next()
+}
+
+// options 返回服务端支持的协议版本、扩展和大小限制。
+func (t tus) options(ctx iris.Context) {
+	ctx.Header("Tus-Version", TusVersion)
+	ctx.Header("Tus-Extension", TusExtension)
+	if t.upload.Tus.Maximum > 0 {
+		ctx.Header("Tus-Max-Size", strconv.FormatInt(t.upload.Tus.Maximum<<20, 10))
+	}
+	ctx.StatusCode(http.StatusNoContent)
+}
+
+// create 创建上传，返回上传地址。
+func (t tus) create(ctx iris.Context) {
+	length, err := strconv.ParseInt(ctx.GetHeader("Upload-Length"), 10, 64)
+	if err != nil || length < 0 {
+		t.stop(ctx, http.StatusBadRequest, err)
+		return
+	}
+	if t.upload.Tus.Maximum > 0 && length > t.upload.Tus.Maximum<<20 {
+		t.stop(ctx, http.StatusRequestEntityTooLarge, nil)
+		return
+	}
+	metadata := t.metadata(ctx.GetHeader("Upload-Metadata"))
+	name := metadata["filename"]
+	if name == "" {
+		name = metadata["name"]
+	}
+	// 提前校验扩展名，避免上传完成后才被拒绝
+	if !t.upload.allowExtension(strings.ToLower(filepath.Ext(name))) {
+		t.stop(ctx, http.StatusUnsupportedMediaType, nil)
+		return
+	}
+	id := strings.ReplaceAll(encrypt.UUID(), "-", "")
+	if err = fsutil.Mkdir(filepath.Dir(t.file(id)), 0755); err != nil {
+		t.stop(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if err = os.WriteFile(t.file(id), nil, 0644); err != nil {
+		t.stop(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	expires := time.Now().Add(t.expire())
+	err = t.rdx.HMSet(t.key(id), map[string]interface{}{
+		"length":   length,
+		"offset":   0,
+		"name":     name,
+		"metadata": ctx.GetHeader("Upload-Metadata"),
+		"expires":  expires.Unix(),
+	}).Err()
+	if err == nil {
+		err = t.rdx.Expire(t.key(id), t.expire()).Err()
+	}
+	if err != nil {
+		_ = os.Remove(t.file(id))
+		t.stop(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	// 空文件不会收到 PATCH 请求，创建时直接完成
+	if length == 0 {
+		value, err := t.complete(id, name)
+		if err != nil {
+			code := uploadCode(err)
+			_ = t.terminate(id)
+			t.stop(ctx, code, err)
+			return
+		}
+		ctx.Header("Upload-Url", value.Url)
+	} else {
+		ctx.Header("Upload-Expires", expires.UTC().Format(http.TimeFormat))
+	}
+	ctx.Header("Location", path.Join(t.route, id))
+	ctx.StatusCode(http.StatusCreated)
+}
+
+// head 返回上传的当前偏移量。
+func (t tus) head(ctx iris.Context) {
+	state, ok := t.state(ctx.Params().Get("id"))
+	if !ok {
+		t.stop(ctx, http.StatusNotFound, nil)
+		return
+	}
+	ctx.Header("Upload-Offset", state["offset"])
+	ctx.Header("Upload-Length", state["length"])
+	if state["metadata"] != "" {
+		ctx.Header("Upload-Metadata", state["metadata"])
+	}
+	if state["url"] != "" {
+		ctx.Header("Upload-Url", state["url"])
+	} else if expires, err := strconv.ParseInt(state["expires"], 10, 64); err == nil {
+		ctx.Header("Upload-Expires", time.Unix(expires, 0).UTC().Format(http.TimeFormat))
+	}
+	ctx.StatusCode(http.StatusOK)
+}
+
+// patch 从请求中的偏移量处追加数据，数据完整后写入存储。
+func (t tus) patch(ctx iris.Context) {
+	id := ctx.Params().Get("id")
+	if ctx.GetHeader("Content-Type") != tusOffset {
+		t.stop(ctx, http.StatusUnsupportedMediaType, nil)
+		return
+	}
+	// 同一上传同时只允许一个 PATCH 请求
+	unlock, ok, err := t.lock(id)
+	if err != nil {
+		t.stop(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if !ok {
+		t.stop(ctx, http.StatusConflict, nil)
+		return
+	}
+	defer unlock()
+	state, ok := t.state(id)
+	if !ok {
+		t.stop(ctx, http.StatusNotFound, nil)
+		return
+	}
+	length, _ := strconv.ParseInt(state["length"], 10, 64)
+	offset, _ := strconv.ParseInt(state["offset"], 10, 64)
+	if state["url"] != "" || ctx.GetHeader("Upload-Offset") != strconv.FormatInt(offset, 10) {
+		t.stop(ctx, http.StatusConflict, nil)
+		return
+	}
+	file, err := os.OpenFile(t.file(id), os.O_WRONLY, 0644)
+	if err != nil {
+		t.stop(ctx, http.StatusNotFound, err)
+		return
+	}
+	// 丢弃上次中断时写入但未记录的数据
+	if err = file.Truncate(offset); err == nil {
+		_, err = file.Seek(offset, io.SeekStart)
+	}
+	if err != nil {
+		_ = file.Close()
+		t.stop(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	// 连接中断时同样记录已经写入的数据，客户端可以从新的偏移量继续上传
+	written, copyErr := io.Copy(file, io.LimitReader(ctx.Request().Body, length-offset))
+	if err = file.Close(); err != nil {
+		t.stop(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	offset += written
+	if err = t.rdx.HSet(t.key(id), "offset", offset).Err(); err != nil {
+		t.stop(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if copyErr != nil {
+		t.stop(ctx, http.StatusInternalServerError, copyErr)
+		return
+	}
+	ctx.Header("Upload-Offset", strconv.FormatInt(offset, 10))
+	if offset == length {
+		value, err := t.complete(id, state["name"])
+		if err != nil {
+			code := uploadCode(err)
+			_ = t.terminate(id)
+			t.stop(ctx, code, err)
+			return
+		}
+		ctx.Header("Upload-Url", value.Url)
+	}
+	ctx.StatusCode(http.StatusNoContent)
+}
+
+// complete 将完整的文件写入存储，并在上传状态中记录访问地址。
+func (t tus) complete(id, name string) (value Uploaded, err error) {
+	file, err := os.Open(t.file(id))
+	if err != nil {
+		return
+	}
+	value, err = t.upload.store(t.storage, file, name)
+	_ = file.Close()
+	if err != nil {
+		return
+	}
+	_ = os.Remove(t.file(id))
+	err = t.rdx.HSet(t.key(id), "url", value.Url).Err()
+	return
+}
+
+// terminate 删除上传状态和未完成的文件。
+func (t tus) terminate(id string) (err error) {
+	err = t.rdx.Del(t.key(id)).Err()
+	if removeErr := os.Remove(t.file(id)); removeErr != nil && !errors.Is(removeErr, os.ErrNotExist) && err == nil {
+		err = removeErr
+	}
+	return
+}
+
+// remove 终止上传。
+func (t tus) remove(ctx iris.Context) {
+	id := ctx.Params().Get("id")
+	if _, ok := t.state(id); !ok {
+		t.stop(ctx, http.StatusNotFound, nil)
+		return
+	}
+	if err := t.terminate(id); err != nil {
+		t.stop(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	ctx.StatusCode(http.StatusNoContent)
+}
+
+// Tus 挂载 tus 1.0.0 断点续传接口，支持 creation、expiration 和 termination 扩展。
+// 上传状态保存在 Redis 中，未完成的数据保存在 Upload.Tus.Dir 暂存目录下，不会通过静态资源被访问，
+// 上传完成后按照与 Upload 相同的规则校验并写入存储，访问地址通过 Upload-Url 响应头返回。
+// 每个请求使用当前生效的配置，重新加载后的大小和类型限制立即生效。
+//
+// 参数:
+// route string: 接口路由，例如 /files。
+// handlers ...iris.Handler: 在接口之前执行的中间件，例如登录校验。
+//
+// 返回值是 Bootstrap 结构体，允许链式调用。
+func (b Bootstrap) Tus(route string, handlers ...iris.Handler) Bootstrap {
+	cache := new(storages)
+	// 配置、存储和 Redis 客户端在请求时获取，注册路由时不需要连接 Redis
+	connect := func(handle func(t tus, ctx iris.Context)) iris.Handler {
+		return func(ctx iris.Context) {
+			global := b.Current()
+			t := tus{route: route, upload: global.Service.Upload}
+			storage, err := cache.get(t.upload)
+			if err != nil {
+				t.stop(ctx, http.StatusInternalServerError, err)
+				return
+			}
+			rdx, err := global.Rds()
+			if err != nil {
+				t.stop(ctx, http.StatusInternalServerError, fmt.Errorf("tus requires redis: %w", err))
+				return
+			}
+			t.storage, t.rdx = storage, rdx
+			handle(t, ctx)
+		}
+	}
+	options := func(ctx iris.Context) {
+		tus{upload: b.Current().Service.Upload}.options(ctx)
+	}
+	party := b.app.Party(route, append(handlers, tus{}.resumable)...)
+	party.Options("/", options)
+	party.Options("/{id:string}", options)
+	party.Post("/", connect(tus.create))
+	party.Head("/{id:string}", connect(tus.head))
+	party.Patch("/{id:string}", connect(tus.patch))
+	party.Delete("/{id:string}", connect(tus.remove))
+	return b
+}
@@ -0,0 +1,209 @@
+package app
+
+import (
+	`crypto/sha256`
+	`encoding/hex`
+	`errors`
+	`fmt`
+	`io`
+	`io/fs`
+	`mime/multipart`
+	`net/http`
+	`path`
+	`path/filepath`
+	`strings`
+	`time`
+	
+	`github.com/chaodoing/figure/o`
+	`github.com/kataras/iris/v12`
+)
+
+var (
+	ErrUploadEmpty     = errors.New("no file uploaded")           // ErrUploadEmpty 请求中没有文件
+	ErrUploadTooLarge  = errors.New("file exceeds maximum size")  // ErrUploadTooLarge 文件超过大小限制
+	ErrUploadExtension = errors.New("file extension not allowed") // ErrUploadExtension 文件扩展名不在允许列表中
+	ErrUploadMime      = errors.New("file type not allowed")      // ErrUploadMime 文件类型不在允许列表中
+)
+
+// Uploaded 上传成功的文件信息
+type Uploaded struct {
+	Name string `json:"name" xml:"name" yaml:"Name" comment:"原始文件名"` // Name 原始文件名
+	Url  string `json:"url" xml:"url" yaml:"Url" comment:"访问路径"`      // Url 访问路径
+	Size int64  `json:"size" xml:"size" yaml:"Size" comment:"文件大小"`   // Size 文件大小，单位字节
+	Mime string `json:"mime" xml:"mime" yaml:"Mime" comment:"文件类型"`   // Mime 文件类型
+	Hash string `json:"hash" xml:"hash" yaml:"Hash" comment:"文件摘要"`   // Hash 文件内容的 SHA256 摘要
+}
+
+// allowExtension 检查扩展名是否在允许列表中，列表为空时允许所有扩展名。
+func (u Upload) allowExtension(ext string) bool {
+	if len(u.Extensions) == 0 {
+		return true
+	}
+	for _, value := range u.Extensions {
+		if strings.EqualFold("."+strings.TrimPrefix(value, "."), ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowMime 检查文件类型是否在允许列表中，支持以 * 结尾的前缀通配，列表为空时允许所有类型。
+func (u Upload) allowMime(mime string) bool {
+	if len(u.Mimes) == 0 {
+		return true
+	}
+	// 去掉 ; charset=utf-8 之类的参数
+	mime = strings.TrimSpace(strings.Split(mime, ";")[0])
+	for _, value := range u.Mimes {
+		if strings.EqualFold(value, mime) {
+			return true
+		}
+		if strings.HasSuffix(value, "*") && strings.HasPrefix(strings.ToLower(mime), strings.ToLower(strings.TrimSuffix(value, "*"))) {
+			return true
+		}
+	}
+	return false
+}
+
+// Save 校验并保存一个上传的文件。
+// 文件保存在存储中按日期分片的目录下，文件名为内容的 SHA256 摘要加原扩展名，
+// 相同内容的文件只会保存一份。
+//
+// 参数:
+// storage Storage: 文件存储，参见 NewStorage。
+// header *multipart.FileHeader: 上传的文件头信息。
+//
+// 返回值:
+// Uploaded: 保存后的文件信息，Url 为存储返回的访问地址。
+// error: 校验失败或保存过程中遇到的错误。
+func (u Upload) Save(storage Storage, header *multipart.FileHeader) (value Uploaded, err error) {
+	if u.Maximum > 0 && header.Size > u.Maximum<<20 {
+		return value, ErrUploadTooLarge
+	}
+	file, err := header.Open()
+	if err != nil {
+		return
+	}
+	defer file.Close()
+	return u.store(storage, file, header.Filename)
+}
+
+// store 校验文件扩展名和内容类型，按内容摘要命名后写入存储。
+func (u Upload) store(storage Storage, file io.ReadSeeker, name string) (value Uploaded, err error) {
+	ext := strings.ToLower(filepath.Ext(name))
+	if !u.allowExtension(ext) {
+		return value, ErrUploadExtension
+	}
+	// 根据文件内容判断类型，不信任客户端提交的 Content-Type
+	sniff := make([]byte, 512)
+	n, err := io.ReadFull(file, sniff)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return
+	}
+	mime := http.DetectContentType(sniff[:n])
+	if !u.allowMime(mime) {
+		return value, ErrUploadMime
+	}
+	// 计算内容摘要作为文件名
+	if _, err = file.Seek(0, io.SeekStart); err != nil {
+		return
+	}
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return
+	}
+	sum := hex.EncodeToString(hash.Sum(nil))
+	key := path.Join(time.Now().Format("2006/01/02"), sum+ext)
+	// 相同内容已经存在时不再重复写入
+	if _, err = storage.Stat(key); err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			return
+		}
+		if _, err = file.Seek(0, io.SeekStart); err != nil {
+			return
+		}
+		if err = storage.Put(key, file, size, mime); err != nil {
+			return
+		}
+	}
+	return Uploaded{
+		Name: filepath.Base(name),
+		Url:  storage.Url(key),
+		Size: size,
+		Mime: mime,
+		Hash: sum,
+	}, nil
+}
+
+// uploadCode 将上传错误映射为响应状态码。
+func uploadCode(err error) int {
+	var maxBytes *http.MaxBytesError
+	switch {
+	case errors.Is(err, ErrUploadTooLarge), errors.As(err, &maxBytes):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, ErrUploadExtension), errors.Is(err, ErrUploadMime):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, ErrUploadEmpty):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// Upload 注册文件上传接口。
+// 接口接收 multipart/form-data 请求中的所有文件，按当前生效的 Service.Upload 配置校验大小、扩展名和类型后写入配置的存储，
+// 返回 o.Data，其中 Data 为 []Uploaded。
+//
+// 参数:
+// route string: 上传接口的路由，例如 /upload。
+// handlers ...iris.Handler: 在上传之前执行的中间件，例如登录校验。
+//
+// 返回值是 Bootstrap 结构体，允许链式调用。
+func (b Bootstrap) Upload(route string, handlers ...iris.Handler) Bootstrap {
+	cache := new(storages)
+	handlers = append(handlers, func(ctx iris.Context) {
+		// 使用当前生效的配置，存储配置错误时返回 500
+		upload := b.Current().Service.Upload
+		storage, err := cache.get(upload)
+		if err != nil {
+			ctx.StatusCode(http.StatusInternalServerError)
+			o.O(ctx, o.Data{Code: http.StatusInternalServerError, Message: err.Error()})
+			return
+		}
+		// 限制整个请求体的大小，多留 1MB 给表单字段和边界
+		if upload.Maximum > 0 {
+			ctx.SetMaxRequestBodySize(upload.Maximum<<20 + 1<<20)
+		}
+		var headers []*multipart.FileHeader
+		err = ctx.Request().ParseMultipartForm(ctx.Application().ConfigurationReadOnly().GetPostMaxMemory())
+		if err == nil && ctx.Request().MultipartForm != nil {
+			for _, values := range ctx.Request().MultipartForm.File {
+				headers = append(headers, values...)
+			}
+		}
+		if err == nil && len(headers) == 0 {
+			err = ErrUploadEmpty
+		}
+		if err != nil {
+			code := uploadCode(err)
+			ctx.StatusCode(code)
+			o.O(ctx, o.Data{Code: code, Message: err.Error()})
+			return
+		}
+		files := make([]Uploaded, 0, len(headers))
+		for _, header := range headers {
+			value, err := upload.Save(storage, header)
+			if err != nil {
+				code := uploadCode(err)
+				ctx.StatusCode(code)
+				o.O(ctx, o.Data{Code: code, Message: fmt.Sprintf("%s: %s", header.Filename, err.Error())})
+				return
+			}
+			files = append(files, value)
+		}
+		o.O(ctx, o.Data{Code: 0, Message: "OK", Data: files})
+	})
+	b.app.Post(route, handlers...)
+	return b
+}
@@ -0,0 +1,264 @@
+package app
+
+import (
+	`fmt`
+	`net/url`
+	`os`
+	`path/filepath`
+	`strings`
+	`time`
+	
+	`github.com/lestrrat-go/strftime`
+)
+
+// logLevels iris 日志支持的等级
+var logLevels = []string{`disable`, `fatal`, `error`, `warn`, `info`, `debug`}
+
+type (
+	// FieldError 单个配置项的校验错误
+	FieldError struct {
+		Field   string // Field 以 . 分隔的 json 标签路径，例如 service.port
+		Message string // Message 错误说明
+	}
+	
+	// ValidationError 配置校验错误，包含所有未通过校验的配置项
+	ValidationError []FieldError
+	
+	// validator 收集校验错误
+	validator struct {
+		errors ValidationError // errors 已经发现的错误
+	}
+)
+
+// Error 实现 error 接口。
+func (e FieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// Error 实现 error 接口，每个错误占一行。
+func (e ValidationError) Error() string {
+	messages := make([]string, len(e))
+	for i, item := range e {
+		messages[i] = item.Error()
+	}
+	return "invalid config:\n  " + strings.Join(messages, "\n  ")
+}
+
+// add 记录一个错误。
+func (v *validator) add(field, format string, args ...interface{}) {
+	v.errors = append(v.errors, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// required 检查字符串不能为空。
+func (v *validator) required(field, value string) {
+	if strings.TrimSpace(value) == "" {
+		v.add(field, "is required")
+	}
+}
+
+// port 检查端口不能为 0。
+func (v *validator) port(field string, value uint16) {
+	if value == 0 {
+		v.add(field, "must be between 1 and 65535")
+	}
+}
+
+// oneOf 检查值必须是 values 之一，忽略大小写。
+func (v *validator) oneOf(field, value string, values ...string) {
+	for _, item := range values {
+		if strings.EqualFold(item, value) {
+			return
+		}
+	}
+	v.add(field, "%q must be one of %s", value, strings.Join(values, ", "))
+}
+
+// route 检查访问路径必须以 / 开头。
+func (v *validator) route(field, value string) {
+	if !strings.HasPrefix(value, "/") {
+		v.add(field, "%q must start with /", value)
+	}
+}
+
+// logFile 检查日志文件名格式是否正确，以及日志目录是否可以写入。
+func (v *validator) logFile(field, value string) {
+	if value == "" {
+		v.add(field, "is required")
+		return
+	}
+	name, err := strftime.Format(os.ExpandEnv(value), time.Now())
+	if err != nil {
+		v.add(field, "invalid pattern: %v", err)
+		return
+	}
+	if err = writable(filepath.Dir(name)); err != nil {
+		v.add(field, "directory is not writable: %v", err)
+	}
+}
+
+// mysql 检查数据库配置，prefix 是配置项路径的前缀，例如 mysql 或 databases.0.mysql。
+// SQLite 只需要数据库名称，MySQL 还需要字符集。
+func (v *validator) mysql(prefix string, config MySQL) {
+	v.oneOf(prefix+".driver", config.Driver, "", DriverMySQL, DriverPostgres, DriverSQLite)
+	v.required(prefix+".name", config.Name)
+	if config.driver() != DriverSQLite {
+		v.required(prefix+".host", config.Host)
+		v.port(prefix+".port", config.Port)
+		v.required(prefix+".username", config.Username)
+	}
+	if config.driver() == DriverMySQL {
+		v.required(prefix+".charset", config.Charset)
+	}
+	if _, err := time.LoadLocation(config.Timezone); err != nil {
+		v.add(prefix+".timezone", "unknown time zone %q", config.Timezone)
+	}
+	v.oneOf(prefix+".tls", config.TLS, "", "false", "true", "skip-verify", "preferred")
+	if _, err := url.ParseQuery(config.Params); err != nil {
+		v.add(prefix+".params", "invalid query string: %v", err)
+	}
+	if pool := config.Pool; pool.MaxOpenConns > 0 && pool.MaxIdleConns > pool.MaxOpenConns {
+		v.add(prefix+".pool.max_idle_conns", "must not exceed max_open_conns %d", pool.MaxOpenConns)
+	}
+	v.oneOf(prefix+".policy", config.Policy, "", PolicyRoundRobin, PolicyWeighted)
+	for i, replica := range config.Replicas {
+		v.required(fmt.Sprintf("%s.replicas.%d.host", prefix, i), replica.Host)
+		if replica.Weight < 0 {
+			v.add(fmt.Sprintf("%s.replicas.%d.weight", prefix, i), "must not be negative")
+		}
+	}
+	if _, ok := dbLogLevel[config.Logger.Level]; !ok {
+		v.add(prefix+".logger.level", "%q must be one of silent, error, warn, info", config.Logger.Level)
+	}
+	v.logFile(prefix+".logger.file", config.Logger.File)
+}
+
+// writable 检查目录是否可以写入，目录不存在时检查最近的已存在的上级目录，因为日志目录会在使用时创建。
+func writable(dir string) error {
+	for {
+		info, err := os.Stat(dir)
+		if err == nil {
+			if !info.IsDir() {
+				return fmt.Errorf("%s is not a directory", dir)
+			}
+			file, err := os.CreateTemp(dir, ".writable-*")
+			if err != nil {
+				return err
+			}
+			_ = file.Close()
+			return os.Remove(file.Name())
+		}
+		if !os.IsNotExist(err) {
+			return err
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return err
+		}
+		dir = parent
+	}
+}
+
+// within 判断 dir 是否为 parent 或位于 parent 之下。
+func within(dir, parent string) bool {
+	dir, _ = filepath.Abs(dir)
+	parent, _ = filepath.Abs(parent)
+	relative, err := filepath.Rel(parent, dir)
+	return err == nil && relative != ".." && !strings.HasPrefix(relative, ".."+string(filepath.Separator))
+}
+
+// Validate 校验配置，一次返回所有问题。
+// 检查端口范围、必填项、跨域来源与凭证的组合、日志等级、日志目录是否可写、模板分隔符数量、上传和存储配置、断点续传暂存目录不在对外访问的目录中、数据库的驱动、时区、TLS、DSN 参数、连接池和只读副本配置以及命名数据库连接的名称。
+// 模板目录只在调用 Bootstrap.View 时使用，由模板引擎在启动时加载，这里不检查是否存在。
+//
+// 返回值:
+// error: 存在问题时返回 ValidationError，其中每一项包含配置项路径和错误说明；配置正确时返回 nil。
+func (g Global) Validate() error {
+	v := new(validator)
+	// 服务配置
+	v.port("service.port", g.Service.Port)
+	v.oneOf("service.log.level", g.Service.Log.Level, logLevels...)
+	v.logFile("service.log.file", g.Service.Log.File)
+	if g.Service.Cors.MaxAge < 0 {
+		v.add("service.cors.max_age", "must not be negative")
+	}
+	// 允许任意来源携带凭证时，任何网站都可以读取带有用户凭证的响应
+	if g.Service.Cors.Credentials && wildcard(g.Service.Cors.Origins) {
+		v.add("service.cors.origins", "must list explicit origins when credentials are allowed, * is not permitted")
+	}
+	if len(g.Service.Template.Delimit) != 2 || g.Service.Template.Delimit[0] == "" || g.Service.Template.Delimit[1] == "" {
+		v.add("service.template.delimit", "requires exactly 2 non-empty delimiters, got %q", g.Service.Template.Delimit)
+	}
+	for i, resource := range g.Service.Resources {
+		v.route(fmt.Sprintf("service.resources.%d.url", i), resource.Url)
+		v.required(fmt.Sprintf("service.resources.%d.dir", i), resource.Dir)
+	}
+	// 上传配置
+	upload := g.Service.Upload
+	if upload.Maximum <= 0 {
+		v.add("service.upload.maximum", "must be greater than 0")
+	}
+	if upload.Tus.Maximum < 0 {
+		v.add("service.upload.tus.maximum", "must not be negative")
+	}
+	// 未完成的上传不能通过静态资源访问
+	v.required("service.upload.tus.dir", upload.Tus.Dir)
+	if upload.Tus.Dir != "" {
+		served := make([]string, 0, len(g.Service.Resources)+1)
+		for _, resource := range g.Service.Resources {
+			served = append(served, resource.Dir)
+		}
+		if upload.Local() {
+			served = append(served, upload.Resource.Dir)
+		}
+		for _, dir := range served {
+			if dir != "" && within(os.ExpandEnv(upload.Tus.Dir), os.ExpandEnv(dir)) {
+				v.add("service.upload.tus.dir", "must not be inside the served directory %s", dir)
+			}
+		}
+	}
+	v.oneOf("service.upload.driver", upload.Driver, "", DriverLocal, DriverS3)
+	if upload.Local() {
+		v.route("service.upload.resource.url", upload.Resource.Url)
+		v.required("service.upload.resource.dir", upload.Resource.Dir)
+	} else if strings.EqualFold(upload.Driver, DriverS3) {
+		v.required("service.upload.s3.endpoint", upload.S3.Endpoint)
+		v.required("service.upload.s3.bucket", upload.S3.Bucket)
+		// 创建客户端时检查地址格式，不访问网络
+		if upload.S3.Endpoint != "" && upload.S3.Bucket != "" {
+			if _, err := NewS3Storage(upload.S3); err != nil {
+				v.add("service.upload.s3.endpoint", "%v", err)
+			}
+		}
+	}
+	// Redis配置
+	v.required("redis.host", g.Redis.Host)
+	v.port("redis.port", g.Redis.Port)
+	if g.Redis.Db < 0 {
+		v.add("redis.db", "must not be negative")
+	}
+	// 数据库配置
+	v.mysql("mysql", g.MySQL)
+	aliases := make(map[string]bool)
+	for i, database := range g.Databases {
+		field := fmt.Sprintf("databases.%d", i)
+		switch {
+		case database.Alias == "":
+			v.add(field+".alias", "is required")
+			continue
+		case database.Alias == DatabaseDefault:
+			v.add(field+".alias", "%q is reserved for the mysql block", database.Alias)
+			continue
+		case aliases[database.Alias]:
+			v.add(field+".alias", "duplicate alias %q", database.Alias)
+			continue
+		}
+		aliases[database.Alias] = true
+		if config, err := g.Database(database.Alias); err == nil {
+			v.mysql(field+".mysql", config)
+		}
+	}
+	if len(v.errors) == 0 {
+		return nil
+	}
+	return v.errors
+}
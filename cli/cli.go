@@ -0,0 +1,643 @@
+package cli
+
+import (
+	`context`
+	`encoding/json`
+	`encoding/xml`
+	`errors`
+	`flag`
+	`fmt`
+	`io`
+	`os`
+	`os/signal`
+	`path/filepath`
+	`runtime`
+	`strings`
+	`syscall`
+	`text/tabwriter`
+	`time`
+	
+	`github.com/chaodoing/figure/app`
+	`github.com/chaodoing/figure/generator`
+	`github.com/chaodoing/figure/migrations`
+	`github.com/chaodoing/figure/seeds`
+	`github.com/chaodoing/figure/toolkit`
+	`github.com/gookit/goutil/fsutil`
+	`github.com/kataras/iris/v12`
+	`gopkg.in/yaml.v2`
+)
+
+const (
+	migrationDir = "${DIR}/migrations" // migrationDir SQL 迁移文件的默认目录
+	seedDir      = "${DIR}/seeds"      // seedDir 种子文件的默认目录
+)
+
+type (
+	// Application 命令行程序，提供 serve、config、env、routes、migrate、models、seed 和 version 子命令。
+	// 不带子命令运行时等同于 serve。
+	Application struct {
+		Config     string                                 // Config 默认配置文件路径，可通过 --config 覆盖
+		Event      app.EventInterface                     // Event 加载配置时使用的事件处理
+		Modules    []app.Module                           // Modules 应用使用的模块，config init 时同时写入模块的默认配置
+		Setup      func(boot app.Bootstrap) app.Bootstrap // Setup 注册路由、视图和中间件
+		Output     io.Writer                              // Output 命令输出，为空时使用标准输出
+		Migrations []migrations.Migration                 // Migrations 版本迁移，与 --dir 目录中的 SQL 迁移一起执行，可以来自 migrations.Load(embed.FS, dir)
+		Models     []interface{}                          // Models seed 使用的 gorm 模型，种子文件名称与模型的表名相同时按模型写入
+	}
+	
+	// command 子命令
+	command struct {
+		name  string                                                                        // name 子命令名称，多级子命令以空格分隔
+		usage string                                                                        // usage 子命令说明
+		run   func(a Application, flags *flag.FlagSet, config *string, args []string) error // run 执行子命令
+	}
+)
+
+// commands 返回所有子命令，按帮助信息中的顺序排列。
+func commands() []command {
+	return []command{
+		{name: "serve", usage: "启动服务", run: serve},
+		{name: "config init", usage: "生成默认配置文件，格式由 --format 或文件扩展名决定", run: configInit},
+		{name: "config check", usage: "加载并校验配置文件，--connect 同时检查数据库和Redis连接", run: configCheck},
+		{name: "config print", usage: "输出合并环境配置、.env.yml 和环境变量后的有效配置，敏感字段脱敏，--sources 输出每个配置项的值、来源和对应的环境变量", run: configPrint},
+		{name: "config schema", usage: "生成配置文件的 JSON Schema，xml 格式生成 XSD，可用于编辑器补全和 CI 校验", run: configSchema},
+		{name: "env make", usage: "根据配置文件生成 ${DIR}/.env.yml", run: envMake},
+		{name: "routes", usage: "列出已注册的路由，不连接数据库和Redis", run: routes},
+		{name: "migrate up", usage: "执行尚未执行的版本迁移，--steps 限制执行数量", run: migrateUp},
+		{name: "migrate down", usage: "回滚最近执行的版本迁移，默认回滚 1 个，--steps 0 回滚全部", run: migrateDown},
+		{name: "migrate status", usage: "列出版本迁移及其执行状态", run: migrateStatus},
+		{name: "migrate create", usage: "在 --dir 目录中创建一对空的 SQL 迁移文件，例如 migrate create create_users", run: migrateCreate},
+		{name: "migrate", usage: "按依赖顺序执行模块的数据迁移", run: migrate},
+		{name: "models", usage: "读取 MySQL 的 information_schema，在 --output 目录中为每个数据表生成 gorm 模型，--tables 指定表名", run: models},
+		{name: "seed", usage: "按依赖顺序写入 --dir 目录和 --env 子目录中的种子数据，--truncate 先清空种子表", run: seed},
+		{name: "key generate", usage: "生成 Base64 编码的 32 字节随机主密钥，写入 " + app.MasterKeyEnv + " 或密钥文件后用于 encrypt 和 ENC(...) 解密", run: keyGenerate},
+		{name: "encrypt", usage: "使用 " + app.MasterKeyEnv + " 或 " + app.MasterKeyFileEnv + " 指定的主密钥加密配置值，输出可写入配置文件的 ENC(...)，未提供值时从标准输入读取", run: encrypt},
+		{name: "version", usage: "显示应用名称、版本和运行环境", run: version},
+	}
+}
+
+// Run 解析命令行参数并执行对应的子命令。
+//
+// 参数:
+// args []string: 命令行参数，不包含程序名称，通常为 os.Args[1:]。
+//
+// 返回值:
+// error: 参数错误或子命令执行失败时返回错误。
+func (a Application) Run(args []string) (err error) {
+	if a.Output == nil {
+		a.Output = os.Stdout
+	}
+	if a.Config == "" {
+		a.Config = "./config/app.xml"
+	}
+	// 子命令之前的全局参数
+	root := flag.NewFlagSet(filepath.Base(os.Args[0]), flag.ContinueOnError)
+	root.SetOutput(a.Output)
+	config := root.String("config", a.Config, "配置文件路径")
+	root.Usage = func() { a.usage(root) }
+	if err = root.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return
+	}
+	args = root.Args()
+	if len(args) == 0 {
+		args = []string{"serve"}
+	}
+	if args[0] == "help" {
+		a.usage(root)
+		return
+	}
+	for _, cmd := range commands() {
+		words := strings.Fields(cmd.name)
+		if len(args) < len(words) || strings.Join(args[:len(words)], " ") != cmd.name {
+			continue
+		}
+		// 子命令同样接受 --config，默认值为全局参数的值
+		flags := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+		flags.SetOutput(a.Output)
+		path := flags.String("config", *config, "配置文件路径")
+		if err = cmd.run(a, flags, path, args[len(words):]); errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return
+	}
+	a.usage(root)
+	return fmt.Errorf("unknown command %q", strings.Join(args, " "))
+}
+
+// usage 输出帮助信息。
+func (a Application) usage(root *flag.FlagSet) {
+	_, _ = fmt.Fprintf(a.Output, "Usage: %s [--config file] <command> [arguments]\n\nCommands:\n", root.Name())
+	writer := tabwriter.NewWriter(a.Output, 0, 4, 2, ' ', 0)
+	for _, cmd := range commands() {
+		_, _ = fmt.Fprintf(writer, "  %s\t%s\n", cmd.name, cmd.usage)
+	}
+	_ = writer.Flush()
+	_, _ = fmt.Fprintln(a.Output, "\nFlags:")
+	root.PrintDefaults()
+}
+
+// parse 解析子命令参数，请求帮助时返回 flag.ErrHelp。
+func parse(flags *flag.FlagSet, args []string) error {
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		return fmt.Errorf("%s: unexpected arguments %v", flags.Name(), flags.Args())
+	}
+	return nil
+}
+
+// boot 加载配置文件并创建 Bootstrap，依次注册模块和 Setup 中的路由。
+// connect 为 false 时不打开数据库和Redis连接，连接在首次使用时打开。
+func (a Application) boot(config string, connect bool) (boot app.Bootstrap, err error) {
+	if connect {
+		boot, err = app.New(config, a.Event)
+	} else {
+		boot, err = app.Prepare(config, a.Event)
+	}
+	if err != nil {
+		return
+	}
+	boot = boot.Use(a.Modules...)
+	if a.Setup != nil {
+		boot = a.Setup(boot)
+	}
+	return
+}
+
+// serve 启动服务。
+func serve(a Application, flags *flag.FlagSet, config *string, args []string) (err error) {
+	if err = parse(flags, args); err != nil {
+		return
+	}
+	boot, err := a.boot(*config, true)
+	if err != nil {
+		return
+	}
+	// 收到 SIGINT/SIGTERM 时优雅关闭，启动失败时返回错误而不是 panic
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return boot.Start(ctx, iris.DefaultConfiguration())
+}
+
+// configInit 将默认配置和模块的默认配置写入配置文件。
+func configInit(a Application, flags *flag.FlagSet, config *string, args []string) (err error) {
+	kind := flags.String("format", "", "配置文件格式: xml、json、yaml 或 toml，默认根据文件扩展名判断")
+	force := flags.Bool("force", false, "覆盖已存在的配置文件")
+	if err = parse(flags, args); err != nil {
+		return
+	}
+	file := os.ExpandEnv(*config)
+	if *kind == "" {
+		if *kind = app.Format(file, nil); *kind == "" {
+			*kind = app.FormatXML
+		}
+	}
+	if fsutil.PathExists(file) && !*force {
+		return fmt.Errorf("%s already exists, use --force to overwrite", file)
+	}
+	if err = fsutil.Mkdir(filepath.Dir(file), 0755); err != nil {
+		return
+	}
+	global := app.GlobalDefault().WithModules(a.Modules...)
+	switch strings.ToLower(*kind) {
+	case app.FormatXML:
+		err = toolkit.SaveXML(global, file)
+	case app.FormatJSON:
+		err = toolkit.SaveJSON(global, file)
+	case app.FormatYAML, "yml":
+		err = toolkit.SaveYAML(global, file)
+	case app.FormatTOML:
+		err = toolkit.SaveTOML(global, file)
+	default:
+		err = fmt.Errorf("unsupported config format %q", *kind)
+	}
+	if err != nil {
+		return
+	}
+	_, err = fmt.Fprintf(a.Output, "config written to %s\n", file)
+	return
+}
+
+// configCheck 加载并校验配置文件、解码模块配置，可选检查数据库和Redis连接。
+func configCheck(a Application, flags *flag.FlagSet, config *string, args []string) (err error) {
+	connect := flags.Bool("connect", false, "同时检查数据库和Redis连接")
+	if err = parse(flags, args); err != nil {
+		return
+	}
+	global, err := app.Load(*config, a.Event)
+	if err != nil {
+		return fmt.Errorf("%s: %w", *config, err)
+	}
+	if err = global.Validate(); err != nil {
+		return fmt.Errorf("%s: %w", *config, err)
+	}
+	for _, module := range a.Modules {
+		if value := module.Config(); value != nil {
+			if _, err = global.Modules.Decode(module.Name(), value); err != nil {
+				return
+			}
+		}
+	}
+	_, _ = fmt.Fprintf(a.Output, "config %s ok\n", *config)
+	if !*connect {
+		return
+	}
+	health := global.Health()
+	for _, probe := range health.Probes {
+		_, _ = fmt.Fprintf(a.Output, "%-8s %-5s %s %s\n", probe.Name, probe.Status, probe.Latency, probe.Error)
+	}
+	_ = global.Close()
+	if health.Status != app.StatusUp {
+		return fmt.Errorf("dependency check failed")
+	}
+	return
+}
+
+// configPrint 输出脱敏后的有效配置。
+func configPrint(a Application, flags *flag.FlagSet, config *string, args []string) (err error) {
+	kind := flags.String("format", "", "输出格式: xml、json、yaml 或 toml，默认与配置文件相同")
+	sources := flags.Bool("sources", false, "输出每个配置项的脱敏值、来源和对应的环境变量")
+	if err = parse(flags, args); err != nil {
+		return
+	}
+	global, err := app.Load(*config, a.Event)
+	if err != nil {
+		return
+	}
+	if *sources {
+		writer := tabwriter.NewWriter(a.Output, 0, 4, 2, ' ', 0)
+		_, _ = fmt.Fprintln(writer, "NAME\tVALUE\tSOURCE\tENV")
+		for _, setting := range global.Effective().Settings {
+			_, _ = fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", setting.Name, setting.Value, setting.Source, setting.Env)
+		}
+		return writer.Flush()
+	}
+	if *kind == "" {
+		*kind = app.Format(*config, nil)
+	}
+	content, err := encode(*kind, global.Redact())
+	if err != nil {
+		return
+	}
+	_, err = a.Output.Write(content)
+	return
+}
+
+// configSchema 根据默认配置和模块的默认配置生成 JSON Schema 或 XSD。
+func configSchema(a Application, flags *flag.FlagSet, config *string, args []string) (err error) {
+	kind := flags.String("format", "", "配置文件格式: xml、json、yaml 或 toml，默认与配置文件相同，xml 生成 XSD")
+	output := flags.String("output", "", "输出文件路径，默认输出到标准输出")
+	if err = parse(flags, args); err != nil {
+		return
+	}
+	if *kind == "" {
+		if *kind = app.Format(*config, nil); *kind == "" {
+			*kind = app.FormatXML
+		}
+	}
+	global := app.GlobalDefault().WithModules(a.Modules...)
+	var content []byte
+	if strings.EqualFold(*kind, app.FormatXML) {
+		content, err = global.XSD()
+	} else {
+		content, err = global.JSONSchema(*kind)
+	}
+	if err != nil {
+		return
+	}
+	if *output == "" {
+		_, err = a.Output.Write(content)
+		return
+	}
+	file := os.ExpandEnv(*output)
+	if err = fsutil.Mkdir(filepath.Dir(file), 0755); err != nil {
+		return
+	}
+	if err = os.WriteFile(file, content, 0644); err != nil {
+		return
+	}
+	_, err = fmt.Fprintf(a.Output, "schema written to %s\n", file)
+	return
+}
+
+// encode 按照格式编码配置，格式为空时使用 xml。
+func encode(format string, global app.Global) (content []byte, err error) {
+	switch strings.ToLower(format) {
+	case app.FormatXML, "":
+		if content, err = xml.MarshalIndent(global, "", "\t"); err == nil {
+			content = append([]byte(xml.Header), append(content, '\n')...)
+		}
+	case app.FormatJSON:
+		if content, err = json.MarshalIndent(global, "", "\t"); err == nil {
+			content = append(content, '\n')
+		}
+	case app.FormatYAML, "yml":
+		content, err = yaml.Marshal(global)
+	case app.FormatTOML:
+		content, err = toolkit.MarshalTOML(global)
+	default:
+		err = fmt.Errorf("unsupported config format %q", format)
+	}
+	return
+}
+
+// envMake 根据配置文件生成 ${DIR}/.env.yml，配置文件不存在时使用默认配置。
+func envMake(a Application, flags *flag.FlagSet, config *string, args []string) (err error) {
+	if err = parse(flags, args); err != nil {
+		return
+	}
+	global := app.GlobalDefault()
+	if fsutil.PathExists(os.ExpandEnv(*config)) {
+		if global, err = app.Load(*config, a.Event); err != nil {
+			return
+		}
+	}
+	if err = global.WithModules(a.Modules...).MakeEnv(); err != nil {
+		return
+	}
+	_, err = fmt.Fprintf(a.Output, "env written to %s\n", os.ExpandEnv("${DIR}/.env.yml"))
+	return
+}
+
+// routes 列出已注册的路由。
+func routes(a Application, flags *flag.FlagSet, config *string, args []string) (err error) {
+	if err = parse(flags, args); err != nil {
+		return
+	}
+	// 只注册路由，不需要连接数据库和Redis，可以在 CI 中离线运行
+	boot, err := a.boot(*config, false)
+	if err != nil {
+		return
+	}
+	defer boot.Global.Close()
+	list, err := boot.Routes()
+	if err != nil {
+		return
+	}
+	writer := tabwriter.NewWriter(a.Output, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(writer, "METHOD\tPATH\tNAME\tHANDLER")
+	for _, route := range list {
+		_, _ = fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", route.Method(), route.Path(), route.Name(), route.MainHandlerName())
+	}
+	return writer.Flush()
+}
+
+// migrate 执行模块的数据迁移。
+func migrate(a Application, flags *flag.FlagSet, config *string, args []string) (err error) {
+	if err = parse(flags, args); err != nil {
+		return
+	}
+	boot, err := a.boot(*config, true)
+	if err != nil {
+		return
+	}
+	defer boot.Global.Close()
+	if err = boot.Migrate(); err != nil {
+		return
+	}
+	_, err = fmt.Fprintln(a.Output, "migrate ok")
+	return
+}
+
+// migrator 根据 --dir、--database 和 --table 参数创建版本迁移执行器，返回的函数关闭数据库连接。
+func (a Application) migrator(flags *flag.FlagSet, config *string, args []string) (m migrations.Migrator, done func(), err error) {
+	dir := flags.String("dir", migrationDir, "SQL 迁移文件所在目录")
+	database := flags.String("database", "", "命名数据库连接，默认使用 mysql 配置")
+	table := flags.String("table", migrations.TableDefault, "迁移记录表名")
+	if err = parse(flags, args); err != nil {
+		return
+	}
+	global, err := app.Load(*config, a.Event)
+	if err != nil {
+		return
+	}
+	if err = global.Validate(); err != nil {
+		return
+	}
+	list, err := migrations.Dir(*dir)
+	if err != nil {
+		return
+	}
+	db, err := global.DbNamed(*database)
+	if err != nil {
+		return
+	}
+	done = func() { _ = global.Close() }
+	if m, err = migrations.New(db, append(list, a.Migrations...)...); err != nil {
+		done()
+		return
+	}
+	m = m.Table(*table)
+	return
+}
+
+// migrateUp 执行尚未执行的版本迁移。
+func migrateUp(a Application, flags *flag.FlagSet, config *string, args []string) (err error) {
+	steps := flags.Int("steps", 0, "最多执行的迁移数量，0 表示全部执行")
+	m, done, err := a.migrator(flags, config, args)
+	if err != nil {
+		return
+	}
+	defer done()
+	applied, err := m.Up(*steps)
+	for _, migration := range applied {
+		_, _ = fmt.Fprintf(a.Output, "up %s %s\n", migration.Version, migration.Name)
+	}
+	if err == nil {
+		_, err = fmt.Fprintf(a.Output, "%d migrations applied\n", len(applied))
+	}
+	return
+}
+
+// migrateDown 回滚最近执行的版本迁移。
+func migrateDown(a Application, flags *flag.FlagSet, config *string, args []string) (err error) {
+	steps := flags.Int("steps", 1, "最多回滚的迁移数量，0 表示全部回滚")
+	m, done, err := a.migrator(flags, config, args)
+	if err != nil {
+		return
+	}
+	defer done()
+	reverted, err := m.Down(*steps)
+	for _, migration := range reverted {
+		_, _ = fmt.Fprintf(a.Output, "down %s %s\n", migration.Version, migration.Name)
+	}
+	if err == nil {
+		_, err = fmt.Fprintf(a.Output, "%d migrations reverted\n", len(reverted))
+	}
+	return
+}
+
+// migrateStatus 列出版本迁移及其执行状态。
+func migrateStatus(a Application, flags *flag.FlagSet, config *string, args []string) (err error) {
+	m, done, err := a.migrator(flags, config, args)
+	if err != nil {
+		return
+	}
+	defer done()
+	list, err := m.Status()
+	if err != nil {
+		return
+	}
+	writer := tabwriter.NewWriter(a.Output, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(writer, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+	for _, status := range list {
+		state, at := "pending", ""
+		if status.Applied {
+			state, at = "applied", status.AppliedAt.Format(time.DateTime)
+		}
+		if status.Missing {
+			state = "missing"
+		}
+		_, _ = fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", status.Version, status.Name, state, at)
+	}
+	return writer.Flush()
+}
+
+// migrateCreate 创建一对空的 SQL 迁移文件。
+func migrateCreate(a Application, flags *flag.FlagSet, config *string, args []string) (err error) {
+	dir := flags.String("dir", migrationDir, "SQL 迁移文件所在目录")
+	if err = flags.Parse(args); err != nil {
+		return
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("%s: requires exactly one migration name", flags.Name())
+	}
+	files, err := migrations.Create(*dir, flags.Arg(0))
+	for _, file := range files {
+		_, _ = fmt.Fprintf(a.Output, "created %s\n", file)
+	}
+	return
+}
+
+// models 根据数据表结构生成 gorm 模型。
+func models(a Application, flags *flag.FlagSet, config *string, args []string) (err error) {
+	output := flags.String("output", "./models", "模型文件输出目录")
+	pkg := flags.String("package", "", "模型的包名，默认使用输出目录名称")
+	tables := flags.String("tables", "", "以 , 分隔的表名，默认生成所有数据表")
+	database := flags.String("database", "", "命名数据库连接，默认使用 mysql 配置")
+	if err = parse(flags, args); err != nil {
+		return
+	}
+	global, err := app.Load(*config, a.Event)
+	if err != nil {
+		return
+	}
+	db, err := global.DbNamed(*database)
+	if err != nil {
+		return
+	}
+	defer global.Close()
+	var names []string
+	for _, name := range strings.Split(*tables, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	list, err := generator.Read(db, names...)
+	if err != nil {
+		return
+	}
+	files, err := generator.Write(*output, *pkg, list)
+	for _, file := range files {
+		_, _ = fmt.Fprintf(a.Output, "generated %s\n", file)
+	}
+	return
+}
+
+// seed 写入种子数据。
+func seed(a Application, flags *flag.FlagSet, config *string, args []string) (err error) {
+	dir := flags.String("dir", seedDir, "种子文件所在目录")
+	env := flags.String("env", os.Getenv("ENV"), "环境名称，同时读取目录中该名称的子目录")
+	database := flags.String("database", "", "命名数据库连接，默认使用 mysql 配置")
+	truncate := flags.Bool("truncate", false, "写入之前清空种子表")
+	if err = parse(flags, args); err != nil {
+		return
+	}
+	global, err := app.Load(*config, a.Event)
+	if err != nil {
+		return
+	}
+	if err = global.Validate(); err != nil {
+		return
+	}
+	db, err := global.DbNamed(*database)
+	if err != nil {
+		return
+	}
+	defer global.Close()
+	seeder, err := seeds.New(db).Model(a.Models...)
+	if err != nil {
+		return
+	}
+	if seeder, err = seeder.Load(*dir, *env); err != nil {
+		return
+	}
+	fixtures, err := seeder.Fixtures()
+	if err != nil {
+		return
+	}
+	if *truncate {
+		err = seeder.Reload()
+	} else {
+		err = seeder.Seed()
+	}
+	if err != nil {
+		return
+	}
+	for _, fixture := range fixtures {
+		_, _ = fmt.Fprintf(a.Output, "seed %s %d rows\n", fixture.Table, len(fixture.Rows))
+	}
+	return
+}
+
+// keyGenerate 生成新的主密钥。
+func keyGenerate(a Application, flags *flag.FlagSet, config *string, args []string) (err error) {
+	if err = parse(flags, args); err != nil {
+		return
+	}
+	key, err := app.GenerateMasterKey()
+	if err != nil {
+		return
+	}
+	_, err = fmt.Fprintln(a.Output, key)
+	return
+}
+
+// encrypt 使用主密钥加密配置值。
+func encrypt(a Application, flags *flag.FlagSet, config *string, args []string) (err error) {
+	if err = flags.Parse(args); err != nil {
+		return
+	}
+	// 主密钥只从环境变量或密钥文件读取，避免出现在命令行参数和 shell 历史中
+	key, err := app.MasterKey()
+	if err != nil {
+		return
+	}
+	value := strings.Join(flags.Args(), " ")
+	if flags.NArg() == 0 {
+		content, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+		value = strings.TrimRight(string(content), "\r\n")
+	}
+	sealed, err := app.Encrypt(value, key)
+	if err != nil {
+		return
+	}
+	_, err = fmt.Fprintln(a.Output, sealed)
+	return
+}
+
+// version 显示 APP、VERSION 和 ENV 环境变量。
+func version(a Application, flags *flag.FlagSet, config *string, args []string) (err error) {
+	if err = parse(flags, args); err != nil {
+		return
+	}
+	_, err = fmt.Fprintf(a.Output, "%s %s (%s, %s)\n", os.Getenv("APP"), os.Getenv("VERSION"), os.Getenv("ENV"), runtime.Version())
+	return
+}
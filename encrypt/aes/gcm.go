@@ -0,0 +1,62 @@
+package aes
+
+import (
+	`crypto/aes`
+	`crypto/cipher`
+	`crypto/rand`
+	`encoding/base64`
+	`errors`
+	`io`
+)
+
+// ErrAuthentication 密文被篡改或密钥不正确，GCM 认证失败
+var ErrAuthentication = errors.New("aes-gcm: message authentication failed")
+
+// EncryptGCM 使用AES的GCM模式对原始数据进行加密，每次加密使用随机的 nonce
+// plaintext: 需要加密的原始数据
+// chars: 用于加密的密钥，长度为16, 24或32字节
+// 返回值 value: Base64 编码的 nonce、密文和认证标签；err: 密钥长度不正确或读取随机数失败时的错误
+func EncryptGCM(plaintext, chars string) (value string, err error) {
+	block, err := aes.NewCipher([]byte(chars))
+	if err != nil {
+		return
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return
+	}
+	// nonce 放在密文之前，解密时从密文中取出
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return
+	}
+	return base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, []byte(plaintext), nil)), nil
+}
+
+// DecryptGCM 使用AES的GCM模式解密并校验数据
+// ciphertext: EncryptGCM 返回的 Base64 编码的数据
+// chars: 解密所需的秘钥，长度为16, 24或32字节
+// 返回值 value: 解密后的数据；err: 密文格式或密钥长度不正确时的错误，认证失败时返回 ErrAuthentication
+func DecryptGCM(ciphertext, chars string) (value string, err error) {
+	encrypted, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return
+	}
+	block, err := aes.NewCipher([]byte(chars))
+	if err != nil {
+		return
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return
+	}
+	if len(encrypted) < gcm.NonceSize()+gcm.Overhead() {
+		return "", errors.New("aes-gcm: ciphertext too short")
+	}
+	nonce, sealed := encrypted[:gcm.NonceSize()], encrypted[gcm.NonceSize():]
+	decrypted, err := gcm.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return "", ErrAuthentication
+	}
+	return string(decrypted), nil
+}
@@ -0,0 +1,330 @@
+package generator
+
+import (
+	`bytes`
+	`fmt`
+	`go/format`
+	`os`
+	`path/filepath`
+	`sort`
+	`strconv`
+	`strings`
+	`text/template`
+	`unicode`
+	
+	`gorm.io/gorm`
+)
+
+// initialisms 生成字段名称时全部大写的缩写
+var initialisms = map[string]bool{
+	"ACL": true, "API": true, "DB": true, "DNS": true, "HTML": true, "HTTP": true, "HTTPS": true, "ID": true,
+	"IP": true, "JSON": true, "MD5": true, "QPS": true, "SQL": true, "SSL": true, "TCP": true, "TLS": true,
+	"TTL": true, "UID": true, "UI": true, "URI": true, "URL": true, "UUID": true, "XML": true,
+}
+
+type (
+	// Table information_schema 中的数据表
+	Table struct {
+		Name    string   // Name 表名
+		Comment string   // Comment 表注释
+		Columns []Column // Columns 按照定义顺序排列的列
+	}
+	
+	// Column information_schema 中的列
+	Column struct {
+		Table    string  `gorm:"column:TABLE_NAME"`     // Table 所属的表名
+		Name     string  `gorm:"column:COLUMN_NAME"`    // Name 列名
+		DataType string  `gorm:"column:DATA_TYPE"`      // DataType 数据类型，例如 int、varchar
+		Type     string  `gorm:"column:COLUMN_TYPE"`    // Type 完整的列类型，例如 int(10) unsigned
+		Nullable string  `gorm:"column:IS_NULLABLE"`    // Nullable 是否允许为空 YES/NO
+		Key      string  `gorm:"column:COLUMN_KEY"`     // Key 索引类型，主键为 PRI
+		Extra    string  `gorm:"column:EXTRA"`          // Extra 附加信息，例如 auto_increment
+		Default  *string `gorm:"column:COLUMN_DEFAULT"` // Default 默认值
+		Comment  string  `gorm:"column:COLUMN_COMMENT"` // Comment 列注释
+	}
+	
+	// field 模板中使用的字段
+	field struct {
+		Name    string // Name 字段名称
+		Type    string // Type Go 类型
+		Tag     string // Tag 结构体标签
+		Comment string // Comment 行尾注释
+	}
+)
+
+// model 模型文件模板
+var model = template.Must(template.New("model").Parse(`// Code generated by figure from information_schema; DO NOT EDIT.
+
+package {{ .Package }}
+
+import (
+{{- range .Standard }}
+	"{{ . }}"
+{{- end }}
+{{ range .Imports }}
+	"{{ . }}"
+{{- end }}
+)
+
+// {{ .Name }} {{ .Comment }}
+type {{ .Name }} struct {
+	XMLName xml.Name ` + "`" + `gorm:"-" json:"-" xml:"{{ .Element }}" yaml:"-"` + "`" + ` // XMLName XML 节点名称，不对应数据表的列
+{{- range .Fields }}
+	{{ .Name }} {{ .Type }} ` + "`" + `{{ .Tag }}` + "`" + ` // {{ .Comment }}
+{{- end }}
+}
+
+// TableName 返回 {{ .Name }} 对应的表名。
+func ({{ .Name }}) TableName() string {
+	return "{{ .Table }}"
+}
+`))
+
+// Read 通过 information_schema 读取当前数据库中的数据表及其列，只支持 MySQL。
+//
+// 参数:
+// db *gorm.DB: 数据库连接，通常为 Global.Db() 或 Global.DbNamed(name) 的返回值。
+// tables ...string: 需要读取的表名，为空时读取所有数据表。
+//
+// 返回值:
+// list []Table: 按表名排序的数据表。
+// err error: 查询失败或指定的表不存在时返回错误。
+func Read(db *gorm.DB, tables ...string) (list []Table, err error) {
+	if name := db.Dialector.Name(); name != "mysql" {
+		return nil, fmt.Errorf("generator: %s is not supported, only mysql", name)
+	}
+	query := db.Table("information_schema.TABLES").Select("TABLE_NAME, TABLE_COMMENT").
+		Where("TABLE_SCHEMA = DATABASE() AND TABLE_TYPE = ?", "BASE TABLE").Order("TABLE_NAME")
+	if len(tables) > 0 {
+		query = query.Where("TABLE_NAME IN ?", tables)
+	}
+	rows, err := query.Rows()
+	if err != nil {
+		return
+	}
+	index := make(map[string]int)
+	for rows.Next() {
+		var table Table
+		if err = rows.Scan(&table.Name, &table.Comment); err != nil {
+			_ = rows.Close()
+			return
+		}
+		index[table.Name] = len(list)
+		list = append(list, table)
+	}
+	if err = rows.Close(); err != nil {
+		return
+	}
+	for _, name := range tables {
+		if _, ok := index[name]; !ok {
+			return nil, fmt.Errorf("generator: table %s does not exist", name)
+		}
+	}
+	var columns []Column
+	query = db.Table("information_schema.COLUMNS").
+		Select("TABLE_NAME, COLUMN_NAME, DATA_TYPE, COLUMN_TYPE, IS_NULLABLE, COLUMN_KEY, EXTRA, COLUMN_DEFAULT, COLUMN_COMMENT").
+		Where("TABLE_SCHEMA = DATABASE()").Order("TABLE_NAME, ORDINAL_POSITION")
+	if len(tables) > 0 {
+		query = query.Where("TABLE_NAME IN ?", tables)
+	}
+	if err = query.Find(&columns).Error; err != nil {
+		return
+	}
+	for _, column := range columns {
+		if i, ok := index[column.Table]; ok {
+			list[i].Columns = append(list[i].Columns, column)
+		}
+	}
+	return
+}
+
+// Write 为每个数据表生成一个模型文件，文件名称为小写的表名加 _gen.go 后缀，已经存在的文件会被覆盖。
+// 固定的后缀避免 order_test、x_linux 之类的表名生成测试文件或带有构建约束的文件。
+//
+// 参数:
+// dir string: 输出目录，不存在时创建，支持环境变量。
+// pkg string: 包名，为空时使用目录名称。
+// tables []Table: 数据表。
+//
+// 返回值:
+// files []string: 生成的文件路径。
+// err error: 生成或写入失败时返回错误。
+func Write(dir, pkg string, tables []Table) (files []string, err error) {
+	dir = os.ExpandEnv(dir)
+	if pkg == "" {
+		pkg = filepath.Base(dir)
+	}
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return
+	}
+	for _, table := range tables {
+		content, err := table.Model(pkg)
+		if err != nil {
+			return files, err
+		}
+		file := filepath.Join(dir, fileName(table.Name))
+		if err = os.WriteFile(file, content, 0644); err != nil {
+			return files, err
+		}
+		files = append(files, file)
+	}
+	return
+}
+
+// Model 生成数据表对应的 gorm 模型源码，字段带有与 o.Data 相同风格的 json、xml、yaml 和 comment 标签。
+// DATE、DATETIME/TIMESTAMP 和 TIME 类型的列分别使用 o.Date、o.Datetime 和 o.Time，允许为空的列使用指针。
+//
+// 参数:
+// pkg string: 包名。
+//
+// 返回值:
+// []byte: 经过 gofmt 格式化的源码。
+// error: 生成失败时返回错误。
+func (t Table) Model(pkg string) ([]byte, error) {
+	imports := map[string]bool{"encoding/xml": true}
+	fields := make([]field, 0, len(t.Columns))
+	for _, column := range t.Columns {
+		kind, path := column.goType()
+		if path != "" {
+			imports[path] = true
+		}
+		name := Camel(column.Name)
+		settings := []string{"column:" + column.Name}
+		if column.Key == "PRI" {
+			settings = append(settings, "primaryKey")
+		}
+		if strings.Contains(column.Extra, "auto_increment") {
+			settings = append(settings, "autoIncrement")
+		}
+		tag := fmt.Sprintf(`gorm:"%s" json:"%s" xml:"%s" yaml:"%s"`, strings.Join(settings, ";"), column.Name, lowerFirst(name), name)
+		if column.Comment != "" {
+			tag += fmt.Sprintf(" comment:%s", strconv.Quote(column.Comment))
+		}
+		comment := strings.TrimSpace(name + " " + oneLine(column.Comment))
+		fields = append(fields, field{Name: name, Type: kind, Tag: tag, Comment: comment})
+	}
+	// 标准库和第三方包分为两组
+	var standard, paths []string
+	for path := range imports {
+		if strings.Contains(strings.Split(path, "/")[0], ".") {
+			paths = append(paths, path)
+		} else {
+			standard = append(standard, path)
+		}
+	}
+	sort.Strings(standard)
+	sort.Strings(paths)
+	name := Camel(t.Name)
+	comment := oneLine(t.Comment)
+	if comment == "" {
+		comment = t.Name + " 表"
+	}
+	var content bytes.Buffer
+	err := model.Execute(&content, map[string]interface{}{
+		"Package":  pkg,
+		"Standard": standard,
+		"Imports":  paths,
+		"Name":     name,
+		"Comment":  comment,
+		"Element":  lowerFirst(name),
+		"Table":    t.Name,
+		"Fields":   fields,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return format.Source(content.Bytes())
+}
+
+// goType 返回列对应的 Go 类型以及需要导入的包。
+func (c Column) goType() (kind string, path string) {
+	unsigned := strings.Contains(strings.ToLower(c.Type), "unsigned")
+	integer := func(bits string) string {
+		if unsigned {
+			return "uint" + bits
+		}
+		return "int" + bits
+	}
+	switch strings.ToLower(c.DataType) {
+	case "tinyint":
+		kind = integer("8")
+	case "smallint", "year":
+		kind = integer("16")
+	case "mediumint", "int", "integer":
+		kind = integer("32")
+	case "bigint":
+		kind = integer("64")
+	case "bit", "bool", "boolean":
+		kind = "bool"
+	case "float":
+		kind = "float32"
+	case "double", "real", "decimal", "numeric":
+		kind = "float64"
+	case "date":
+		kind, path = "o.Date", "github.com/chaodoing/figure/o"
+	case "datetime", "timestamp":
+		kind, path = "o.Datetime", "github.com/chaodoing/figure/o"
+	case "time":
+		kind, path = "o.Time", "github.com/chaodoing/figure/o"
+	case "binary", "varbinary", "tinyblob", "blob", "mediumblob", "longblob":
+		return "[]byte", ""
+	default:
+		kind = "string"
+	}
+	if c.Nullable == "YES" && c.Key != "PRI" {
+		kind = "*" + kind
+	}
+	return
+}
+
+// Camel 将下划线分隔的名称转换为大驼峰，常见缩写全部大写，例如 user_id => UserID。
+func Camel(name string) string {
+	var camel strings.Builder
+	for _, word := range strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if upper := strings.ToUpper(word); initialisms[upper] {
+			camel.WriteString(upper)
+			continue
+		}
+		runes := []rune(word)
+		camel.WriteString(string(unicode.ToUpper(runes[0])) + string(runes[1:]))
+	}
+	result := camel.String()
+	if result == "" || unicode.IsDigit([]rune(result)[0]) {
+		result = "T" + result
+	}
+	return result
+}
+
+// lowerFirst 将名称转换为小驼峰，开头连续的大写字母一起转换为小写，例如 UserID => userID、UUIDValue => uuidValue。
+func lowerFirst(name string) string {
+	runes := []rune(name)
+	for i := range runes {
+		if !unicode.IsUpper(runes[i]) || i > 0 && i+1 < len(runes) && unicode.IsLower(runes[i+1]) {
+			break
+		}
+		runes[i] = unicode.ToLower(runes[i])
+	}
+	return string(runes)
+}
+
+// fileName 返回数据表对应的文件名称，字母和数字以外的字符替换为 _，例如 order-item => order_item_gen.go。
+// 去掉开头的 _，以 _ 开头的文件会被 go 构建忽略。
+func fileName(table string) string {
+	name := strings.TrimLeft(strings.Map(func(r rune) rune {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return unicode.ToLower(r)
+		}
+		return '_'
+	}, table), "_")
+	if name == "" {
+		name = "table"
+	}
+	return name + "_gen.go"
+}
+
+// oneLine 将多行注释合并为一行。
+func oneLine(comment string) string {
+	return strings.Join(strings.Fields(comment), " ")
+}
@@ -1,11 +1,14 @@
 package main
 
 import (
+	`fmt`
 	`os`
 	
 	`github.com/chaodoing/figure/app`
+	`github.com/chaodoing/figure/cli`
 	`github.com/chaodoing/figure/models`
 	`github.com/chaodoing/figure/o`
+	`github.com/chaodoing/figure/resource`
 	`github.com/gookit/goutil/envutil`
 	`github.com/kataras/iris/v12`
 	`github.com/kataras/iris/v12/hero`
@@ -41,14 +44,18 @@ func main() {
 		"APP":     envutil.Getenv("APP", APP),
 		"VERSION": envutil.Getenv("VERSION", VERSION),
 	})
-	boot, err := app.New("./config/app.xml", false, nil)
+	err := cli.Application{
+		Config: "./config/app.xml",
+		Setup: func(boot app.Bootstrap) app.Bootstrap {
+			return boot.Handle(func(app *iris.Application) {
+				app.Get(`/index`, hero.Handler(index))
+			}).Mvc(func(app *mvc.Application) {
+				app.Party("/api").Handle(&Controller{Resource: resource.New[models.Admin]()}).Name = "api"
+			})
+		},
+	}.Run(os.Args[1:])
 	if err != nil {
-		panic(err)
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
-	
-	boot.Handle(func(app *iris.Application) {
-		app.Get(`/index`, hero.Handler(index))
-	}).Mvc(func(app *mvc.Application) {
-		app.Party("/api").Handle(new(Controller)).Name = "api"
-	}).Run(iris.DefaultConfiguration())
 }
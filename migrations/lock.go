@@ -0,0 +1,87 @@
+package migrations
+
+import (
+	`context`
+	`database/sql`
+	`errors`
+	`fmt`
+	`time`
+	
+	`gorm.io/gorm`
+)
+
+// lock 获取数据库的咨询锁，返回释放锁的函数；ctx 超时之前没有获取到锁时返回错误。
+// 锁保存在从连接池中取出的单独连接上，迁移完成后释放锁并归还连接。
+// MySQL 使用 GET_LOCK，PostgreSQL 使用 pg_try_advisory_lock，SQLite 依靠数据库文件锁，不需要咨询锁。
+func lock(ctx context.Context, db *gorm.DB, table string) (unlock func() error, err error) {
+	unlock = func() error { return nil }
+	dialect := db.Dialector.Name()
+	if dialect != "mysql" && dialect != "postgres" {
+		return
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return
+	}
+	// 锁名称包含数据库名称，同一个服务器上的不同数据库互不影响
+	name := fmt.Sprintf("figure.migrations.%s.%s", db.Migrator().CurrentDatabase(), table)
+	if dialect == "mysql" {
+		err = mysqlLock(ctx, conn, name)
+	} else {
+		err = postgresLock(ctx, conn, name)
+	}
+	if err != nil {
+		_ = conn.Close()
+		return
+	}
+	unlock = func() (err error) {
+		if dialect == "mysql" {
+			_, err = conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", name)
+		} else {
+			_, err = conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock(hashtext($1))", name)
+		}
+		if value := conn.Close(); err == nil {
+			err = value
+		}
+		return
+	}
+	return
+}
+
+// mysqlLock 使用 GET_LOCK 获取锁，等待时间为 ctx 的剩余时间。
+func mysqlLock(ctx context.Context, conn *sql.Conn, name string) error {
+	timeout := 0
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = int(time.Until(deadline).Seconds())
+	}
+	var locked sql.NullInt64
+	if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", name, timeout).Scan(&locked); err != nil {
+		return err
+	}
+	if !locked.Valid || locked.Int64 != 1 {
+		return errors.New("timeout waiting for migration lock " + name)
+	}
+	return nil
+}
+
+// postgresLock 每隔一段时间尝试获取锁，直到获取成功或 ctx 结束。
+func postgresLock(ctx context.Context, conn *sql.Conn, name string) error {
+	for {
+		var locked bool
+		if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock(hashtext($1))", name).Scan(&locked); err != nil {
+			return err
+		}
+		if locked {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return errors.New("timeout waiting for migration lock " + name)
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
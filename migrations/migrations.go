@@ -0,0 +1,242 @@
+package migrations
+
+import (
+	`context`
+	`fmt`
+	`sort`
+	`time`
+	
+	`github.com/chaodoing/figure/app`
+	`gorm.io/gorm`
+)
+
+// TableDefault 记录已执行迁移的默认表名
+const TableDefault = "schema_migrations"
+
+type (
+	// Migration 单个版本的迁移，版本号按字符串顺序执行，通常使用 20060102150405 格式的时间。
+	Migration struct {
+		Version string               // Version 版本号
+		Name    string               // Name 迁移名称
+		Up      func(*gorm.DB) error // Up 升级，在事务中执行
+		Down    func(*gorm.DB) error // Down 回滚，在事务中执行，为空时不能回滚
+	}
+	
+	// Record 迁移记录表中的一行
+	Record struct {
+		Version   string    `gorm:"primaryKey;size:64"` // Version 版本号
+		Name      string    `gorm:"size:255"`           // Name 迁移名称
+		AppliedAt time.Time // AppliedAt 执行时间
+	}
+	
+	// Status 迁移的执行状态
+	Status struct {
+		Version   string    `json:"version" xml:"version" yaml:"Version"`        // Version 版本号
+		Name      string    `json:"name" xml:"name" yaml:"Name"`                 // Name 迁移名称
+		Applied   bool      `json:"applied" xml:"applied" yaml:"Applied"`        // Applied 是否已经执行
+		AppliedAt time.Time `json:"applied_at" xml:"appliedAt" yaml:"AppliedAt"` // AppliedAt 执行时间
+		Missing   bool      `json:"missing" xml:"missing" yaml:"Missing"`        // Missing 已经执行但迁移已不存在
+	}
+	
+	// Migrator 迁移执行器，执行期间持有数据库的咨询锁，多个实例同时执行时依次进行。
+	Migrator struct {
+		db         *gorm.DB      // db 数据库连接
+		table      string        // table 迁移记录表名
+		timeout    time.Duration // timeout 等待咨询锁的最长时间
+		migrations []Migration   // migrations 按版本排序的迁移
+	}
+)
+
+// New 创建迁移执行器。
+//
+// 参数:
+// db *gorm.DB: 数据库连接，通常为 Global.Db() 或 Global.DbNamed(name) 的返回值。
+// migrations []Migration: 所有迁移，可以来自 Load 或 Go 代码。
+//
+// 返回值:
+// Migrator: 迁移执行器。
+// error: 版本号为空、重复或缺少 Up 时返回错误。
+func New(db *gorm.DB, migrations ...Migration) (m Migrator, err error) {
+	m = Migrator{db: db, table: TableDefault, timeout: time.Minute}
+	versions := make(map[string]bool)
+	for _, migration := range migrations {
+		switch {
+		case migration.Version == "":
+			return m, fmt.Errorf("migration %q has no version", migration.Name)
+		case versions[migration.Version]:
+			return m, fmt.Errorf("duplicate migration version %s", migration.Version)
+		case migration.Up == nil:
+			return m, fmt.Errorf("migration %s has no up", migration.Version)
+		}
+		versions[migration.Version] = true
+		m.migrations = append(m.migrations, migration)
+	}
+	sort.Slice(m.migrations, func(i, j int) bool {
+		return m.migrations[i].Version < m.migrations[j].Version
+	})
+	return
+}
+
+// Table 设置迁移记录表名。
+// 返回值是 Migrator 结构体，允许链式调用。
+func (m Migrator) Table(name string) Migrator {
+	m.table = name
+	return m
+}
+
+// Timeout 设置等待咨询锁的最长时间。
+// 返回值是 Migrator 结构体，允许链式调用。
+func (m Migrator) Timeout(timeout time.Duration) Migrator {
+	m.timeout = timeout
+	return m
+}
+
+// Up 按版本顺序执行尚未执行的迁移。
+//
+// 参数:
+// steps int: 最多执行的迁移数量，0 表示全部执行。
+//
+// 返回值:
+// applied []Migration: 本次执行的迁移。
+// err error: 加锁或迁移失败时返回错误，失败之前执行的迁移保持已执行状态。
+func (m Migrator) Up(steps int) (applied []Migration, err error) {
+	err = m.locked(func(records map[string]Record) error {
+		for _, migration := range m.migrations {
+			if _, ok := records[migration.Version]; ok {
+				continue
+			}
+			if steps > 0 && len(applied) >= steps {
+				return nil
+			}
+			if err := m.run(migration, migration.Up, true); err != nil {
+				return err
+			}
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+	return
+}
+
+// Down 按版本倒序回滚已经执行的迁移。
+//
+// 参数:
+// steps int: 最多回滚的迁移数量，0 表示全部回滚。
+//
+// 返回值:
+// reverted []Migration: 本次回滚的迁移。
+// err error: 加锁或回滚失败、已执行的迁移不存在或没有 Down 时返回错误。
+func (m Migrator) Down(steps int) (reverted []Migration, err error) {
+	known := make(map[string]Migration)
+	for _, migration := range m.migrations {
+		known[migration.Version] = migration
+	}
+	err = m.locked(func(records map[string]Record) error {
+		versions := make([]string, 0, len(records))
+		for version := range records {
+			versions = append(versions, version)
+		}
+		sort.Sort(sort.Reverse(sort.StringSlice(versions)))
+		for _, version := range versions {
+			if steps > 0 && len(reverted) >= steps {
+				return nil
+			}
+			migration, ok := known[version]
+			if !ok {
+				return fmt.Errorf("migration %s is applied but missing", version)
+			}
+			if migration.Down == nil {
+				return fmt.Errorf("migration %s has no down", version)
+			}
+			if err := m.run(migration, migration.Down, false); err != nil {
+				return err
+			}
+			reverted = append(reverted, migration)
+		}
+		return nil
+	})
+	return
+}
+
+// Status 返回所有迁移的执行状态，按版本排序，已经执行但不存在的迁移标记为 Missing。
+//
+// 返回值:
+// list []Status: 迁移状态。
+// err error: 读取迁移记录失败时返回错误。
+func (m Migrator) Status() (list []Status, err error) {
+	records, err := m.records()
+	if err != nil {
+		return
+	}
+	for _, migration := range m.migrations {
+		record, ok := records[migration.Version]
+		list = append(list, Status{Version: migration.Version, Name: migration.Name, Applied: ok, AppliedAt: record.AppliedAt})
+		delete(records, migration.Version)
+	}
+	for _, record := range records {
+		list = append(list, Status{Version: record.Version, Name: record.Name, Applied: true, AppliedAt: record.AppliedAt, Missing: true})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Version < list[j].Version
+	})
+	return
+}
+
+// session 返回使用主库的会话，迁移和迁移记录不读取只读副本。
+func (m Migrator) session() *gorm.DB {
+	return app.Primary(m.db.Session(&gorm.Session{NewDB: true}))
+}
+
+// records 创建迁移记录表并读取所有记录。
+func (m Migrator) records() (records map[string]Record, err error) {
+	if err = m.session().Table(m.table).AutoMigrate(&Record{}); err != nil {
+		return
+	}
+	var list []Record
+	if err = m.session().Table(m.table).Find(&list).Error; err != nil {
+		return
+	}
+	records = make(map[string]Record, len(list))
+	for _, record := range list {
+		records[record.Version] = record
+	}
+	return
+}
+
+// locked 持有咨询锁读取迁移记录并执行 handle。
+func (m Migrator) locked(handle func(records map[string]Record) error) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), m.timeout)
+	defer cancel()
+	unlock, err := lock(ctx, m.db, m.table)
+	if err != nil {
+		return
+	}
+	defer func() {
+		if value := unlock(); err == nil {
+			err = value
+		}
+	}()
+	records, err := m.records()
+	if err != nil {
+		return
+	}
+	return handle(records)
+}
+
+// run 在事务中执行迁移并更新迁移记录。
+// MySQL 的 DDL 语句会隐式提交事务，迁移失败时已经执行的 DDL 不会回滚；PostgreSQL 和 SQLite 整个迁移一起回滚。
+func (m Migrator) run(migration Migration, handle func(*gorm.DB) error, up bool) error {
+	err := m.session().Transaction(func(tx *gorm.DB) error {
+		if err := handle(tx); err != nil {
+			return err
+		}
+		if up {
+			return tx.Table(m.table).Create(&Record{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now()}).Error
+		}
+		return tx.Table(m.table).Where("version = ?", migration.Version).Delete(&Record{}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("migration %s %s: %w", migration.Version, migration.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package migrations
+
+import (
+	`fmt`
+	`io/fs`
+	`os`
+	`path`
+	`path/filepath`
+	`regexp`
+	`strings`
+	`time`
+	
+	`gorm.io/gorm`
+)
+
+// pattern SQL 迁移文件名称，例如 20240102150405_create_users.up.sql
+var pattern = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Load 读取目录中的 SQL 迁移文件，文件名称格式为 版本号_名称.up.sql 和 版本号_名称.down.sql。
+// 支持 embed.FS 和 os.DirFS，不符合格式的文件被忽略；只有 down 文件的迁移返回错误。
+//
+// 参数:
+// fsys fs.FS: 文件系统。
+// dir string: 迁移文件所在目录。
+//
+// 返回值:
+// migrations []Migration: 迁移，每个文件中的语句以 ; 分隔并依次执行。
+// err error: 读取文件失败时返回错误。
+func Load(fsys fs.FS, dir string) (migrations []Migration, err error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return
+	}
+	index := make(map[string]int)
+	for _, entry := range entries {
+		match := pattern.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		i, ok := index[match[1]]
+		if !ok {
+			i, index[match[1]] = len(migrations), len(migrations)
+			migrations = append(migrations, Migration{Version: match[1], Name: match[2]})
+		}
+		if match[3] == "up" {
+			migrations[i].Up = SQL(string(content))
+		} else {
+			migrations[i].Down = SQL(string(content))
+		}
+	}
+	for _, migration := range migrations {
+		if migration.Up == nil {
+			return nil, fmt.Errorf("migration %s_%s has no up file", migration.Version, migration.Name)
+		}
+	}
+	return
+}
+
+// Dir 读取本地目录中的 SQL 迁移文件，目录路径中的环境变量会被展开，目录不存在时没有迁移。
+func Dir(dir string) ([]Migration, error) {
+	dir = os.ExpandEnv(dir)
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return nil, nil
+	}
+	return Load(os.DirFS(dir), ".")
+}
+
+// SQL 返回依次执行 content 中每条语句的迁移函数，语句以 ; 分隔，字符串和注释中的 ; 不作为分隔符。
+func SQL(content string) func(*gorm.DB) error {
+	statements := Split(content)
+	return func(tx *gorm.DB) error {
+		for _, statement := range statements {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// Split 将 SQL 内容按 ; 拆分为语句，忽略单引号、双引号、反引号字符串以及 -- 和 /* */ 注释中的 ;，空语句被丢弃。
+func Split(content string) (statements []string) {
+	var current strings.Builder
+	flush := func() {
+		if statement := strings.TrimSpace(current.String()); statement != "" {
+			statements = append(statements, statement)
+		}
+		current.Reset()
+	}
+	for i := 0; i < len(content); i++ {
+		char := content[i]
+		switch {
+		case char == '\'' || char == '"' || char == '`':
+			// 字符串或标识符，重复的引号和反斜杠转义不结束字符串
+			end := i + 1
+			for ; end < len(content); end++ {
+				if content[end] == '\\' && char != '`' {
+					end++
+				} else if content[end] == char {
+					if end+1 < len(content) && content[end+1] == char {
+						end++
+						continue
+					}
+					break
+				}
+			}
+			end = min(end, len(content)-1)
+			current.WriteString(content[i : end+1])
+			i = end
+		case strings.HasPrefix(content[i:], "--"):
+			end := strings.IndexByte(content[i:], '\n')
+			if end < 0 {
+				end = len(content) - i
+			}
+			i += end
+			current.WriteByte('\n')
+		case strings.HasPrefix(content[i:], "/*"):
+			end := strings.Index(content[i+2:], "*/")
+			if end < 0 {
+				i = len(content)
+			} else {
+				i += end + 3
+			}
+			current.WriteByte(' ')
+		case char == ';':
+			flush()
+		default:
+			current.WriteByte(char)
+		}
+	}
+	flush()
+	return
+}
+
+// Create 在目录中创建一对空的 SQL 迁移文件，版本号为当前时间。
+//
+// 参数:
+// dir string: 迁移文件所在目录，不存在时创建，支持环境变量。
+// name string: 迁移名称，非字母数字的字符替换为 _。
+//
+// 返回值:
+// files []string: 创建的 up 和 down 文件路径。
+// err error: 创建失败时返回错误。
+func Create(dir, name string) (files []string, err error) {
+	name = strings.Trim(regexp.MustCompile(`\W+`).ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		return nil, fmt.Errorf("migration name is required")
+	}
+	dir = os.ExpandEnv(dir)
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return
+	}
+	version := time.Now().Format("20060102150405")
+	for _, kind := range []string{"up", "down"} {
+		file := filepath.Join(dir, fmt.Sprintf("%s_%s.%s.sql", version, name, kind))
+		// 文件已经存在时不覆盖
+		var handle *os.File
+		if handle, err = os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644); err != nil {
+			return
+		}
+		_, err = fmt.Fprintf(handle, "-- %s %s\n", name, kind)
+		if value := handle.Close(); err == nil {
+			err = value
+		}
+		if err != nil {
+			return
+		}
+		files = append(files, file)
+	}
+	return
+}
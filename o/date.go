@@ -30,3 +30,12 @@ func (t Date) Value() (driver.Value, error) {
 	}
 	return time.Time(t).Format(FORMAT_DATE), nil
 }
+
+// Scan 为 Date 实现 sql.Scanner 接口，从数据库读取时将时间或 FORMAT_DATE 格式的字符串转换为 Date
+func (t *Date) Scan(value interface{}) error {
+	ts, err := scan(value, FORMAT_DATE)
+	if err == nil {
+		*t = Date(ts)
+	}
+	return err
+}
@@ -30,3 +30,12 @@ func (t Datetime) Value() (driver.Value, error) {
 	}
 	return time.Time(t).Format(FORMAT_DATE_TIME), nil
 }
+
+// Scan 为 Datetime 实现 sql.Scanner 接口，从数据库读取时将时间或 FORMAT_DATE_TIME 格式的字符串转换为 Datetime
+func (t *Datetime) Scan(value interface{}) error {
+	ts, err := scan(value, FORMAT_DATE_TIME)
+	if err == nil {
+		*t = Datetime(ts)
+	}
+	return err
+}
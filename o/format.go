@@ -1,6 +1,7 @@
 package o
 
 import (
+	`fmt`
 	`time`
 	
 	`github.com/lestrrat-go/strftime`
@@ -54,3 +55,19 @@ func Format(s string, t time.Time) (data string, err error) {
 	data = p.FormatString(t)
 	return
 }
+
+// scan 将数据库返回的值转换为时间，字符串和字节切片按照 layout 解析，用于实现 sql.Scanner。
+func scan(value interface{}, layout string) (t time.Time, err error) {
+	switch value := value.(type) {
+	case nil:
+		return
+	case time.Time:
+		return value, nil
+	case []byte:
+		return time.ParseInLocation(layout, string(value), time.Local)
+	case string:
+		return time.ParseInLocation(layout, value, time.Local)
+	default:
+		return t, fmt.Errorf("cannot scan %T into %s", value, layout)
+	}
+}
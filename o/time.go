@@ -30,3 +30,12 @@ func (t Time) Value() (driver.Value, error) {
 	}
 	return time.Time(t).Format(FORMAT_TIME), nil
 }
+
+// Scan 为 Time 实现 sql.Scanner 接口，从数据库读取时将时间或 FORMAT_TIME 格式的字符串转换为 Time
+func (t *Time) Scan(value interface{}) error {
+	ts, err := scan(value, FORMAT_TIME)
+	if err == nil {
+		*t = Time(ts)
+	}
+	return err
+}
@@ -0,0 +1,267 @@
+package resource
+
+import (
+	`errors`
+	`net/http`
+	`reflect`
+	
+	`github.com/chaodoing/figure/app`
+	`github.com/chaodoing/figure/o`
+	`github.com/kataras/iris/v12`
+	`gorm.io/gorm`
+	`gorm.io/gorm/clause`
+	`gorm.io/gorm/schema`
+)
+
+const (
+	CodeSuccess  = 0    // CodeSuccess 请求成功
+	CodeInvalid  = 400  // CodeInvalid 请求参数绑定或校验失败
+	CodeNotFound = 404  // CodeNotFound 记录不存在
+	CodeDatabase = 3306 // CodeDatabase 数据库连接或查询失败
+)
+
+// Resource 通用的增删改查 mvc 控制器，T 为 gorm 模型，主键为整数。
+// 注册后提供以下接口，响应使用 o.O 按照 Accept 输出：
+//
+//	GET    /         列表，page 和 size 查询参数分页，返回 o.Pagination
+//	GET    /{id}     详情
+//	POST   /         创建，请求体按 Content-Type 绑定
+//	PUT    /{id}     更新，请求体中的字段覆盖已有记录，主键以路径为准
+//	DELETE /{id}     删除
+//
+// 请求体绑定后调用 iris.Application 的 Validator 以及模型的 Validate 方法（实现 app.ValidatorInterface 时）。
+// 处理方法依赖 app.Global，使用 app.Bootstrap 注册时已经提供。
+// 配置使用不导出的字段，mvc 会把零值的导出字段当作依赖注入。
+//
+//	boot.Mvc(func(app *mvc.Application) {
+//		app.Party("/admin").Handle(&Controller{Resource: resource.New[models.Admin]().Size(20)})
+//	})
+type Resource[T any] struct {
+	database string                                       // database 命名数据库连接，为空时使用默认连接
+	size     int                                          // size 默认每页条数
+	maximum  int                                          // maximum 每页条数的上限
+	scope    func(ctx iris.Context, db *gorm.DB) *gorm.DB // scope 查询条件
+}
+
+// New 创建资源控制器，默认每页 20 条，每页最多 100 条。
+//
+// 返回值:
+// Resource[T]: 资源控制器，可以直接注册或者嵌入其它控制器。
+func New[T any]() Resource[T] {
+	return Resource[T]{size: 20, maximum: 100}
+}
+
+// Database 设置使用的命名数据库连接。
+//
+// 参数:
+// name string: 数据库连接名称，为空或 default 时使用默认连接。
+//
+// 返回值:
+// Resource[T]: 设置后的资源控制器。
+func (r Resource[T]) Database(name string) Resource[T] {
+	r.database = name
+	return r
+}
+
+// Size 设置默认每页条数，小于等于 0 时忽略。
+//
+// 参数:
+// size int: 未指定 size 查询参数时的每页条数。
+//
+// 返回值:
+// Resource[T]: 设置后的资源控制器。
+func (r Resource[T]) Size(size int) Resource[T] {
+	if size > 0 {
+		r.size = size
+	}
+	return r
+}
+
+// Maximum 设置每页条数的上限，小于等于 0 时忽略。
+//
+// 参数:
+// maximum int: size 查询参数允许的最大值。
+//
+// 返回值:
+// Resource[T]: 设置后的资源控制器。
+func (r Resource[T]) Maximum(maximum int) Resource[T] {
+	if maximum > 0 {
+		r.maximum = maximum
+	}
+	return r
+}
+
+// Scope 设置查询条件，列表、详情、更新和删除都会使用，例如按照租户过滤。
+//
+// 参数:
+// scope func(ctx iris.Context, db *gorm.DB) *gorm.DB: 返回附加了查询条件的数据库会话。
+//
+// 返回值:
+// Resource[T]: 设置后的资源控制器。
+func (r Resource[T]) Scope(scope func(ctx iris.Context, db *gorm.DB) *gorm.DB) Resource[T] {
+	r.scope = scope
+	return r
+}
+
+// Get 分页返回记录列表，按主键排序。
+func (r *Resource[T]) Get(ctx iris.Context, global app.Global) {
+	db, ok := r.db(ctx, global)
+	if !ok {
+		return
+	}
+	page := max(ctx.URLParamIntDefault("page", 1), 1)
+	size := min(max(ctx.URLParamIntDefault("size", max(r.size, 1)), 1), max(r.maximum, 1))
+	var total int64
+	if err := db.Model(new(T)).Count(&total).Error; err != nil {
+		r.fail(ctx, http.StatusInternalServerError, CodeDatabase, err)
+		return
+	}
+	list := make([]T, 0, size)
+	query := db.Model(new(T)).Offset((page - 1) * size).Limit(size)
+	if field, err := r.primary(db); err == nil {
+		query = query.Order(clause.OrderByColumn{Column: clause.Column{Name: field.DBName}})
+	}
+	if err := query.Find(&list).Error; err != nil {
+		r.fail(ctx, http.StatusInternalServerError, CodeDatabase, err)
+		return
+	}
+	o.O(ctx, o.Pagination{Page: page, Total: int(total), Size: size, Code: CodeSuccess, Message: "OK", Data: list})
+}
+
+// GetBy 返回指定主键的记录。
+func (r *Resource[T]) GetBy(id uint64, ctx iris.Context, global app.Global) {
+	db, ok := r.db(ctx, global)
+	if !ok {
+		return
+	}
+	if value, ok := r.find(ctx, db, id); ok {
+		o.O(ctx, o.Data{Code: CodeSuccess, Message: "OK", Data: value})
+	}
+}
+
+// Post 绑定并校验请求体后创建记录，请求体中的主键被忽略。
+func (r *Resource[T]) Post(ctx iris.Context, global app.Global) {
+	db, ok := r.db(ctx, global)
+	if !ok {
+		return
+	}
+	value := new(T)
+	if !r.bind(ctx, value) {
+		return
+	}
+	field, err := r.primary(db)
+	if err != nil {
+		r.fail(ctx, http.StatusInternalServerError, CodeDatabase, err)
+		return
+	}
+	field.ReflectValueOf(ctx, reflect.ValueOf(value).Elem()).SetZero()
+	if err = db.Create(value).Error; err != nil {
+		r.fail(ctx, http.StatusInternalServerError, CodeDatabase, err)
+		return
+	}
+	ctx.StatusCode(http.StatusCreated)
+	o.O(ctx, o.Data{Code: CodeSuccess, Message: "Created", Data: value})
+}
+
+// PutBy 将请求体绑定到指定主键的记录上，校验后保存。
+func (r *Resource[T]) PutBy(id uint64, ctx iris.Context, global app.Global) {
+	db, ok := r.db(ctx, global)
+	if !ok {
+		return
+	}
+	value, ok := r.find(ctx, db, id)
+	if !ok || !r.bind(ctx, value) {
+		return
+	}
+	// 主键以路径为准
+	field, err := r.primary(db)
+	if err == nil {
+		err = field.Set(ctx, reflect.ValueOf(value).Elem(), id)
+	}
+	if err == nil {
+		err = db.Save(value).Error
+	}
+	if err != nil {
+		r.fail(ctx, http.StatusInternalServerError, CodeDatabase, err)
+		return
+	}
+	o.O(ctx, o.Data{Code: CodeSuccess, Message: "OK", Data: value})
+}
+
+// DeleteBy 删除指定主键的记录。
+func (r *Resource[T]) DeleteBy(id uint64, ctx iris.Context, global app.Global) {
+	db, ok := r.db(ctx, global)
+	if !ok {
+		return
+	}
+	value, ok := r.find(ctx, db, id)
+	if !ok {
+		return
+	}
+	if err := db.Delete(value).Error; err != nil {
+		r.fail(ctx, http.StatusInternalServerError, CodeDatabase, err)
+		return
+	}
+	o.O(ctx, o.Data{Code: CodeSuccess, Message: "OK", Data: value})
+}
+
+// db 返回应用了 Scope 的数据库会话，连接失败时输出错误并返回 false。
+// 返回的会话上的每个语句都从 Scope 的条件重新开始，查询语句的条件不会带到之后的更新和删除中。
+func (r *Resource[T]) db(ctx iris.Context, global app.Global) (*gorm.DB, bool) {
+	db, err := global.DbNamed(r.database)
+	if err != nil {
+		r.fail(ctx, http.StatusInternalServerError, CodeDatabase, err)
+		return nil, false
+	}
+	db = db.WithContext(ctx)
+	if r.scope != nil {
+		db = r.scope(ctx, db).Session(&gorm.Session{})
+	}
+	return db, true
+}
+
+// find 查询指定主键的记录，记录不存在或查询失败时输出错误并返回 false。
+func (r *Resource[T]) find(ctx iris.Context, db *gorm.DB, id uint64) (*T, bool) {
+	value := new(T)
+	err := db.First(value, id).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		r.fail(ctx, http.StatusNotFound, CodeNotFound, err)
+		return nil, false
+	case err != nil:
+		r.fail(ctx, http.StatusInternalServerError, CodeDatabase, err)
+		return nil, false
+	}
+	return value, true
+}
+
+// bind 按照 Content-Type 绑定请求体并校验，失败时输出错误并返回 false。
+func (r *Resource[T]) bind(ctx iris.Context, value *T) bool {
+	err := ctx.ReadBody(value)
+	if validator, ok := interface{}(value).(app.ValidatorInterface); ok && err == nil {
+		err = validator.Validate()
+	}
+	if err != nil {
+		r.fail(ctx, http.StatusBadRequest, CodeInvalid, err)
+		return false
+	}
+	return true
+}
+
+// primary 返回模型的主键字段。
+func (r *Resource[T]) primary(db *gorm.DB) (*schema.Field, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(new(T)); err != nil {
+		return nil, err
+	}
+	if stmt.Schema.PrioritizedPrimaryField == nil {
+		return nil, errors.New(stmt.Schema.Name + " has no primary key")
+	}
+	return stmt.Schema.PrioritizedPrimaryField, nil
+}
+
+// fail 设置 HTTP 状态码并输出错误信息。
+func (r *Resource[T]) fail(ctx iris.Context, status, code int, err error) {
+	ctx.StatusCode(status)
+	o.O(ctx, o.Data{Code: code, Message: err.Error()})
+}
@@ -0,0 +1,351 @@
+package seeds
+
+import (
+	`fmt`
+	`os`
+	`path/filepath`
+	`reflect`
+	`sort`
+	`strings`
+	`text/template`
+	`time`
+	
+	`github.com/chaodoing/figure/app`
+	`github.com/chaodoing/figure/encrypt`
+	`github.com/chaodoing/figure/toolkit`
+	`gorm.io/gorm`
+	`gorm.io/gorm/schema`
+)
+
+type (
+	// Fixture 一个数据表的种子数据，文件名称（不含扩展名）为表名，例如 users.yml。
+	//
+	//	depends: [roles]
+	//	rows:
+	//	  - id: 1
+	//	    uuid: "{{ uuid }}"
+	//	    name: admin
+	//	    created_at: "{{ days -7 }}"
+	Fixture struct {
+		Table   string                   `json:"-" yaml:"-"`             // Table 表名，来自文件名称
+		File    string                   `json:"-" yaml:"-"`             // File 种子文件路径
+		Depends []string                 `json:"depends" yaml:"depends"` // Depends 依赖的表，依赖的表先写入、后清空
+		Rows    []map[string]interface{} `json:"rows" yaml:"rows"`       // Rows 数据行，键为列名或模型的字段名称，字符串值支持模板
+	}
+	
+	// Seeder 种子数据加载器，按依赖顺序把种子数据写入数据库。
+	Seeder struct {
+		db       *gorm.DB                  // db 数据库连接
+		models   map[string]*schema.Schema // models 表名与模型结构的对应关系
+		fixtures map[string]Fixture        // fixtures 已加载的种子数据，键为表名
+	}
+)
+
+// New 创建种子数据加载器。
+//
+// 参数:
+// db *gorm.DB: 数据库连接，通常为 Global.Db() 或 Global.DbNamed(name) 的返回值。
+//
+// 返回值:
+// Seeder: 种子数据加载器。
+func New(db *gorm.DB) Seeder {
+	return Seeder{db: db, models: make(map[string]*schema.Schema), fixtures: make(map[string]Fixture)}
+}
+
+// Model 注册 gorm 模型，表名由模型决定；有模型的表按照模型的字段类型和钩子写入，没有模型的表直接按列名写入。
+//
+// 参数:
+// models ...interface{}: 模型指针，例如 &User{}。
+//
+// 返回值:
+// Seeder: 注册了模型的 Seeder。
+// error: 模型解析失败时返回错误。
+func (s Seeder) Model(models ...interface{}) (Seeder, error) {
+	registered := make(map[string]*schema.Schema, len(s.models)+len(models))
+	for name, model := range s.models {
+		registered[name] = model
+	}
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: s.db}
+		if err := stmt.Parse(model); err != nil {
+			return s, err
+		}
+		registered[stmt.Schema.Table] = stmt.Schema
+	}
+	s.models = registered
+	return s, nil
+}
+
+// Load 读取目录中的 .yml、.yaml 和 .json 种子文件，env 不为空时再读取 env 子目录，其中的文件替换同名的种子文件。
+//
+// 参数:
+// dir string: 种子文件所在目录，支持环境变量。
+// env string: 环境名称，例如 development、test。
+//
+// 返回值:
+// Seeder: 加载了种子数据的 Seeder。
+// error: 读取或解析失败时返回错误。
+func (s Seeder) Load(dir, env string) (Seeder, error) {
+	fixtures := make(map[string]Fixture, len(s.fixtures))
+	for name, fixture := range s.fixtures {
+		fixtures[name] = fixture
+	}
+	dirs := []string{os.ExpandEnv(dir)}
+	if env != "" {
+		dirs = append(dirs, filepath.Join(dirs[0], env))
+	}
+	for _, dir := range dirs {
+		files, err := filepath.Glob(filepath.Join(dir, "*"))
+		if err != nil {
+			return s, err
+		}
+		for _, file := range files {
+			fixture, err := read(file)
+			if err != nil {
+				return s, fmt.Errorf("seed %s: %w", file, err)
+			}
+			if fixture.Table != "" {
+				fixtures[fixture.Table] = fixture
+			}
+		}
+	}
+	s.fixtures = fixtures
+	return s, nil
+}
+
+// Fixtures 返回已加载的种子数据，按依赖顺序排列。
+//
+// 返回值:
+// []Fixture: 种子数据。
+// error: 依赖的表不存在或存在循环依赖时返回错误。
+func (s Seeder) Fixtures() (list []Fixture, err error) {
+	names := make([]string, 0, len(s.fixtures))
+	for name := range s.fixtures {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	state := make(map[string]int)
+	var visit func(name string, from string) error
+	visit = func(name string, from string) error {
+		fixture, ok := s.fixtures[name]
+		switch {
+		case !ok:
+			return fmt.Errorf("seed %s depends on unknown table %s", from, name)
+		case state[name] == 1:
+			return fmt.Errorf("seed %s has circular dependency", name)
+		case state[name] == 2:
+			return nil
+		}
+		state[name] = 1
+		for _, depend := range fixture.Depends {
+			if err := visit(depend, name); err != nil {
+				return err
+			}
+		}
+		state[name] = 2
+		list = append(list, fixture)
+		return nil
+	}
+	for _, name := range names {
+		if err = visit(name, name); err != nil {
+			return nil, err
+		}
+	}
+	return
+}
+
+// Seed 在一个事务中按依赖顺序写入所有种子数据，字符串值中的模板在写入时求值。
+//
+// 返回值:
+// error: 模板错误或写入失败时返回错误，事务回滚。
+func (s Seeder) Seed() error {
+	return s.transaction(false)
+}
+
+// Truncate 在一个事务中按依赖的相反顺序删除所有种子表中的数据。
+// 使用 DELETE 而不是 TRUNCATE，外键约束和事务在各个数据库中的行为一致，自增序列不会重置，种子数据需要写明主键。
+//
+// 返回值:
+// error: 删除失败时返回错误，事务回滚。
+func (s Seeder) Truncate() error {
+	list, err := s.Fixtures()
+	if err != nil {
+		return err
+	}
+	return app.Primary(s.db).Transaction(func(tx *gorm.DB) error {
+		return s.truncate(tx, list)
+	})
+}
+
+// Reload 在一个事务中清空种子表并重新写入种子数据，用于测试之间重置数据。
+//
+// 返回值:
+// error: 删除或写入失败时返回错误，事务回滚。
+func (s Seeder) Reload() error {
+	return s.transaction(true)
+}
+
+// transaction 在事务中写入种子数据，truncate 为 true 时先清空种子表。
+func (s Seeder) transaction(truncate bool) error {
+	list, err := s.Fixtures()
+	if err != nil {
+		return err
+	}
+	return app.Primary(s.db).Transaction(func(tx *gorm.DB) error {
+		if truncate {
+			if err := s.truncate(tx, list); err != nil {
+				return err
+			}
+		}
+		funcs := Funcs(time.Now())
+		for _, fixture := range list {
+			if err := s.insert(tx, fixture, funcs); err != nil {
+				return fmt.Errorf("seed %s: %w", fixture.Table, err)
+			}
+		}
+		return nil
+	})
+}
+
+// truncate 按依赖的相反顺序删除种子表中的数据。
+func (s Seeder) truncate(tx *gorm.DB, list []Fixture) error {
+	for i := len(list) - 1; i >= 0; i-- {
+		if err := tx.Exec("DELETE FROM " + tx.Statement.Quote(list[i].Table)).Error; err != nil {
+			return fmt.Errorf("truncate %s: %w", list[i].Table, err)
+		}
+	}
+	return nil
+}
+
+// insert 对数据行中的模板求值后写入数据表，注册了模型时先按列名或字段名称赋值给模型。
+func (s Seeder) insert(tx *gorm.DB, fixture Fixture, funcs template.FuncMap) error {
+	if len(fixture.Rows) == 0 {
+		return nil
+	}
+	rows := make([]map[string]interface{}, len(fixture.Rows))
+	for i, row := range fixture.Rows {
+		value, err := render(row, funcs)
+		if err != nil {
+			return fmt.Errorf("row %d: %w", i, err)
+		}
+		rows[i] = value.(map[string]interface{})
+	}
+	model, ok := s.models[fixture.Table]
+	if !ok {
+		return tx.Table(fixture.Table).Create(&rows).Error
+	}
+	records := reflect.MakeSlice(reflect.SliceOf(reflect.PointerTo(model.ModelType)), len(rows), len(rows))
+	for i, row := range rows {
+		record := reflect.New(model.ModelType)
+		for name, value := range row {
+			field := model.LookUpField(name)
+			if field == nil {
+				return fmt.Errorf("row %d: unknown column %s", i, name)
+			}
+			if err := field.Set(tx.Statement.Context, record.Elem(), value); err != nil {
+				return fmt.Errorf("row %d: %w", i, err)
+			}
+		}
+		records.Index(i).Set(record)
+	}
+	return tx.Create(records.Interface()).Error
+}
+
+// read 读取一个种子文件，不支持的扩展名返回空的 Fixture。
+func read(file string) (fixture Fixture, err error) {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yml", ".yaml":
+		err = toolkit.ReadYAML(file, &fixture)
+	case ".json":
+		err = toolkit.ReadJSON(file, &fixture)
+	default:
+		return
+	}
+	if err != nil {
+		return
+	}
+	fixture.File = file
+	fixture.Table = strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	return
+}
+
+// Funcs 返回种子数据模板可以使用的函数，相对时间以 now 为基准，结果为 RFC3339 格式的字符串。
+//
+//	uuid           生成 UUID
+//	now            当前时间
+//	today          当天零点
+//	days N         N 天之后，N 为负数时表示之前
+//	hours N        N 小时之后
+//	minutes N      N 分钟之后
+//	env "NAME"     环境变量
+func Funcs(now time.Time) template.FuncMap {
+	format := func(value time.Time) string {
+		return value.Format(time.RFC3339)
+	}
+	return template.FuncMap{
+		"uuid": encrypt.UUID,
+		"now": func() string {
+			return format(now)
+		},
+		"today": func() string {
+			return format(time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()))
+		},
+		"days": func(n int) string {
+			return format(now.AddDate(0, 0, n))
+		},
+		"hours": func(n int) string {
+			return format(now.Add(time.Duration(n) * time.Hour))
+		},
+		"minutes": func(n int) string {
+			return format(now.Add(time.Duration(n) * time.Minute))
+		},
+		"env": os.Getenv,
+	}
+}
+
+// render 将 YAML 解码得到的 map[interface{}]interface{} 转换为 map[string]interface{}，并对包含 {{ 的字符串求值。
+func render(value interface{}, funcs template.FuncMap) (interface{}, error) {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		data := make(map[string]interface{}, len(value))
+		for key, item := range value {
+			item, err := render(item, funcs)
+			if err != nil {
+				return nil, err
+			}
+			data[key] = item
+		}
+		return data, nil
+	case map[interface{}]interface{}:
+		data := make(map[string]interface{}, len(value))
+		for key, item := range value {
+			data[fmt.Sprint(key)] = item
+		}
+		return render(data, funcs)
+	case []interface{}:
+		data := make([]interface{}, len(value))
+		for i, item := range value {
+			item, err := render(item, funcs)
+			if err != nil {
+				return nil, err
+			}
+			data[i] = item
+		}
+		return data, nil
+	case string:
+		if !strings.Contains(value, "{{") {
+			return value, nil
+		}
+		tpl, err := template.New("seed").Funcs(funcs).Parse(value)
+		if err != nil {
+			return nil, err
+		}
+		var text strings.Builder
+		if err = tpl.Execute(&text, nil); err != nil {
+			return nil, err
+		}
+		return text.String(), nil
+	default:
+		return value, nil
+	}
+}
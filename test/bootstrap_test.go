@@ -0,0 +1,216 @@
+package test
+
+import (
+	`context`
+	`fmt`
+	`net`
+	`net/http`
+	`os`
+	`path/filepath`
+	`reflect`
+	`sync`
+	`testing`
+	`time`
+	
+	`github.com/chaodoing/figure/app`
+	`github.com/kataras/iris/v12`
+)
+
+type (
+	// recorder 按顺序记录关闭过程中的事件
+	recorder struct {
+		mutex  sync.Mutex
+		events []string
+	}
+	
+	// lifecycle 记录迁移和关闭顺序的模块
+	lifecycle struct {
+		name    string
+		depends []string
+		record  *recorder
+	}
+)
+
+func (r *recorder) add(format string, args ...interface{}) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.events = append(r.events, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) list() []string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([]string(nil), r.events...)
+}
+
+func (m *lifecycle) Name() string                                            { return m.name }
+func (m *lifecycle) Config() interface{}                                     { return nil }
+func (m *lifecycle) Depends() []string                                       { return m.depends }
+func (m *lifecycle) Register(app *iris.Application, global app.Global) error { return nil }
+func (m *lifecycle) Migrate(global app.Global) error {
+	m.record.add("migrate %s", m.name)
+	return nil
+}
+func (m *lifecycle) Shutdown(global app.Global) error {
+	m.record.add("module %s", m.name)
+	return nil
+}
+
+// freePort 返回一个空闲的本地端口
+func freePort(t *testing.T) int {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+// bootConfig 写入使用 SQLite 和指定 Redis 的配置文件，service 为追加到 service 节点的 JSON 字段
+func bootConfig(t *testing.T, dir string, port int, redis *net.TCPAddr, service string) string {
+	file := filepath.Join(dir, "app.json")
+	content := fmt.Sprintf(`{
+	"service": {"host": "127.0.0.1", "port": %d, "log": {"console": false, "file": %q, "level": "error"}%s},
+	"mysql": {"driver": "sqlite", "name": %q, "logger": {"console": false, "file": %q}},
+	"redis": {"host": %q, "port": %d, "auth": ""}
+}`, port, filepath.Join(dir, "app.log"), service, filepath.Join(dir, "app.db"), filepath.Join(dir, "mysql.log"), redis.IP.String(), redis.Port)
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestGraceful(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("DIR", dir)
+	port := freePort(t)
+	boot, err := app.New(bootConfig(t, dir, port, pong(t), ""), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := boot.Global.Db()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	record := &recorder{}
+	started, release := make(chan struct{}), make(chan struct{})
+	boot = boot.Use(&lifecycle{name: "b", depends: []string{"a"}, record: record}, &lifecycle{name: "a", record: record}).
+		OnShutdown(func(global app.Global) {
+			record.add("hook ping=%v", sqlDB.Ping() == nil)
+		}).
+		Handle(func(application *iris.Application) {
+			application.Get("/slow", func(ctx iris.Context) {
+				close(started)
+				<-release
+				record.add("request closing=%v", boot.Closing())
+				ctx.WriteString("done")
+			})
+		})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	finished := make(chan error, 1)
+	go func() {
+		finished <- boot.Start(ctx, iris.DefaultConfiguration())
+	}()
+	// 等待服务启动后发起一个处理中的请求
+	responded := make(chan error, 1)
+	go func() {
+		for begin := time.Now(); time.Since(begin) < 5*time.Second; time.Sleep(20 * time.Millisecond) {
+			res, err := http.Get(fmt.Sprintf("http://127.0.0.1:%d/slow", port))
+			if err == nil {
+				_ = res.Body.Close()
+				responded <- nil
+				return
+			}
+		}
+		responded <- fmt.Errorf("server did not start")
+	}()
+	select {
+	case <-started:
+	case err = <-responded:
+		t.Fatal(err)
+	}
+	cancel()
+	for begin := time.Now(); !boot.Closing(); time.Sleep(10 * time.Millisecond) {
+		if time.Since(begin) > 5*time.Second {
+			t.Fatal("closing flag not set")
+		}
+	}
+	// 处理中的请求完成之前不执行关闭钩子
+	time.Sleep(50 * time.Millisecond)
+	if events := record.list(); len(events) != 0 {
+		t.Errorf("shutdown before request finished %v", events)
+	}
+	close(release)
+	if err = <-responded; err != nil {
+		t.Error(err)
+	}
+	if err = <-finished; err != nil {
+		t.Fatal(err)
+	}
+	expect := []string{"request closing=true", "hook ping=true", "module b", "module a"}
+	if events := record.list(); !reflect.DeepEqual(events, expect) {
+		t.Errorf("events %v", events)
+	}
+	if sqlDB.Ping() == nil {
+		t.Error("database still open")
+	}
+}
+
+func TestShutdownDelay(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("DIR", dir)
+	port := freePort(t)
+	boot, err := app.New(bootConfig(t, dir, port, pong(t), `, "shutdown_delay": 1`), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	boot = boot.Health()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	finished := make(chan error, 1)
+	go func() {
+		finished <- boot.Start(ctx, iris.DefaultConfiguration())
+	}()
+	ready := func() (int, error) {
+		res, err := http.Get(fmt.Sprintf("http://127.0.0.1:%d/readyz", port))
+		if err != nil {
+			return 0, err
+		}
+		_ = res.Body.Close()
+		return res.StatusCode, nil
+	}
+	for begin := time.Now(); ; time.Sleep(20 * time.Millisecond) {
+		if status, err := ready(); err == nil {
+			if status != http.StatusOK {
+				t.Fatalf("ready %d", status)
+			}
+			break
+		}
+		if time.Since(begin) > 5*time.Second {
+			t.Fatal("server did not start")
+		}
+	}
+	begin := time.Now()
+	cancel()
+	for !boot.Closing() {
+		time.Sleep(10 * time.Millisecond)
+	}
+	// 等待期间仍然接收请求，就绪检查返回 503
+	if status, err := ready(); err != nil || status != http.StatusServiceUnavailable {
+		t.Errorf("ready while draining %d %v", status, err)
+	}
+	if err = <-finished; err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(begin); elapsed < time.Second {
+		t.Errorf("shutdown finished in %s", elapsed)
+	}
+	if _, err = ready(); err == nil {
+		t.Error("listener still open")
+	}
+}
@@ -0,0 +1,83 @@
+package test
+
+import (
+	`bytes`
+	`net`
+	`path/filepath`
+	`strings`
+	`testing`
+	
+	`github.com/chaodoing/figure/app`
+	`github.com/chaodoing/figure/cli`
+)
+
+func TestCli(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("DIR", dir)
+	var output bytes.Buffer
+	application := cli.Application{
+		Modules: []app.Module{&adminModule{config: admin{Route: "/admin", Size: 20}}},
+		Output:  &output,
+		Setup: func(boot app.Bootstrap) app.Bootstrap {
+			return boot.Health().Tus("/files")
+		},
+	}
+	for _, name := range []string{"app.xml", "app.json", "app.yaml", "app.toml"} {
+		file := filepath.Join(dir, name)
+		if err := application.Run([]string{"--config", file, "config", "init"}); err != nil {
+			t.Error(name, err)
+			continue
+		}
+		if err := application.Run([]string{"config", "init", "--config", file}); err == nil {
+			t.Error(name, "overwrite without --force")
+		}
+	}
+	for _, name := range []string{"app.xml", "app.json", "app.yaml", "app.toml"} {
+		if err := application.Run([]string{"--config", filepath.Join(dir, name), "config", "check"}); err != nil {
+			t.Error(name, err)
+		}
+	}
+	if err := application.Run([]string{"--config", filepath.Join(dir, "app.json"), "env", "make"}); err != nil {
+		t.Error(err)
+	}
+	// 默认配置中的 MySQL 和 Redis 不可用时同样可以列出路由
+	if err := application.Run([]string{"--config", filepath.Join(dir, "app.xml"), "routes"}); err != nil {
+		t.Error(err)
+	}
+	if !strings.Contains(output.String(), "/readyz") || !strings.Contains(output.String(), "/files/{id:string}") {
+		t.Error(output.String())
+	}
+	if err := application.Run([]string{"version"}); err != nil {
+		t.Error(err)
+	}
+	var key bytes.Buffer
+	if err := (cli.Application{Output: &key}).Run([]string{"key", "generate"}); err != nil {
+		t.Error(err)
+	}
+	if _, err := app.Encrypt("secret", strings.TrimSpace(key.String())); err != nil {
+		t.Errorf("generated key %q %v", key.String(), err)
+	}
+	if err := application.Run([]string{"unknown"}); err == nil {
+		t.Error("unknown command accepted")
+	}
+	if !strings.Contains(output.String(), "config written to") {
+		t.Error(output.String())
+	}
+	t.Log(output.String())
+}
+
+func TestCliServe(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("DIR", dir)
+	// 端口被占用时返回错误，不 panic
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	file := bootConfig(t, dir, listener.Addr().(*net.TCPAddr).Port, pong(t), "")
+	var output bytes.Buffer
+	if err = (cli.Application{Output: &output}).Run([]string{"--config", file, "serve"}); err == nil {
+		t.Error("serve on a used port returned nil")
+	}
+}
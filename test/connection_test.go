@@ -0,0 +1,198 @@
+package test
+
+import (
+	`bufio`
+	`net`
+	`testing`
+	`time`
+	
+	`github.com/chaodoing/figure/app`
+)
+
+// pong 启动一个对所有命令都回复 PONG 的 Redis 服务
+func pong(t *testing.T) *net.TCPAddr {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				reader := bufio.NewReader(conn)
+				for {
+					line, err := reader.ReadString('\n')
+					if err != nil {
+						return
+					}
+					// 只在命令的第一行（参数个数）回复
+					if line[0] == '*' {
+						if _, err = conn.Write([]byte("+PONG\r\n")); err != nil {
+							return
+						}
+					}
+				}
+			}(conn)
+		}
+	}()
+	return listener.Addr().(*net.TCPAddr)
+}
+
+func TestConnection(t *testing.T) {
+	addr := pong(t)
+	global := app.GlobalDefault()
+	global.Redis.Host, global.Redis.Port, global.Redis.Auth = addr.IP.String(), uint16(addr.Port), ""
+	first, err := global.Rds()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 副本共享同一个连接
+	copied := global.WithModules()
+	second, err := copied.Rds()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != second {
+		t.Error("copies opened separate clients")
+	}
+	if err = copied.Close(); err != nil {
+		t.Error(err)
+	}
+	third, err := global.Rds()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if third == first {
+		t.Error("closed client returned")
+	}
+	_ = global.Close()
+	// 首次连接失败时按照重试配置重试
+	global.Redis.Port = 1
+	global.Redis.Retry = app.Retry{Attempts: 2, Delay: 50, MaxDelay: 60}
+	begin := time.Now()
+	if _, err = global.Rds(); err == nil {
+		t.Error("connected to closed port")
+	}
+	if elapsed := time.Since(begin); elapsed < 110*time.Millisecond {
+		t.Errorf("retry finished in %s", elapsed)
+	}
+}
+
+func TestRedisBlocked(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("DIR", dir)
+	// 接受连接但不回复的 Redis 服务，PING 一直等待到读取超时
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	accepted := make(chan net.Conn, 8)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			accepted <- conn
+		}
+	}()
+	global, err := app.Load(bootConfig(t, dir, freePort(t), listener.Addr().(*net.TCPAddr), ""), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	global.Redis.Retry = app.Retry{}
+	dialed := make(chan error, 1)
+	go func() {
+		_, err := global.Rds()
+		dialed <- err
+	}()
+	var conn net.Conn
+	select {
+	case conn = <-accepted:
+	case <-time.After(5 * time.Second):
+		t.Fatal("redis not dialed")
+	}
+	// Redis 阻塞时仍然可以获取数据库连接
+	opened := make(chan error, 1)
+	go func() {
+		_, err := global.Db()
+		opened <- err
+	}()
+	select {
+	case err = <-opened:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Error("Db blocked behind the redis dial")
+	}
+	_ = listener.Close()
+	_ = conn.Close()
+	if err = <-dialed; err == nil {
+		t.Error("silent redis accepted")
+	}
+	_ = global.Close()
+}
+
+func TestDatabaseBlocked(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("DIR", dir)
+	// 接受连接但不发送握手的数据库，建立连接一直等待
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	accepted := make(chan net.Conn, 8)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			accepted <- conn
+		}
+	}()
+	global, err := app.Load(bootConfig(t, dir, freePort(t), pong(t), ""), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().(*net.TCPAddr)
+	global.MySQL.Driver, global.MySQL.Host, global.MySQL.Port = app.DriverMySQL, addr.IP.String(), uint16(addr.Port)
+	global.MySQL.Name, global.MySQL.Username, global.MySQL.ReadTimeout, global.MySQL.Retry = "app", "root", 0, app.Retry{}
+	opened := make(chan error, 1)
+	go func() {
+		_, err := global.Db()
+		opened <- err
+	}()
+	var conn net.Conn
+	select {
+	case conn = <-accepted:
+	case <-time.After(5 * time.Second):
+		t.Fatal("database not dialed")
+	}
+	// 数据库阻塞时仍然可以获取 Redis 客户端
+	dialed := make(chan error, 1)
+	go func() {
+		_, err := global.Rds()
+		dialed <- err
+	}()
+	select {
+	case err = <-dialed:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Error("Rds blocked behind the database dial")
+	}
+	_ = listener.Close()
+	_ = conn.Close()
+	if err = <-opened; err == nil {
+		t.Error("silent database accepted")
+	}
+	_ = global.Close()
+}
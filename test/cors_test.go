@@ -0,0 +1,114 @@
+package test
+
+import (
+	`net/http`
+	`net/http/httptest`
+	`strings`
+	`testing`
+	
+	`github.com/chaodoing/figure/app`
+	`github.com/kataras/iris/v12`
+)
+
+// corsApp 创建安装了跨域中间件的应用，/ping 返回 200
+func corsApp(t *testing.T, cors app.Cors) *iris.Application {
+	application := iris.New()
+	application.UseRouter(cors.Handler())
+	application.Get("/ping", func(ctx iris.Context) {
+		ctx.WriteString("pong")
+	})
+	if err := application.Build(); err != nil {
+		t.Fatal(err)
+	}
+	return application
+}
+
+func TestCors(t *testing.T) {
+	cors := app.Cors{
+		Origins: []string{"https://*.example.com", "http://localhost:8080"},
+		Methods: []string{"GET", "POST"},
+		Headers: []string{"Content-Type"},
+		Expose:  []string{"X-Total"},
+		MaxAge:  600,
+	}
+	credentials := cors
+	credentials.Credentials = true
+	// 允许携带凭证时 * 不匹配任何来源
+	unsafe := credentials
+	unsafe.Origins = []string{"*"}
+	anyone := cors
+	anyone.Origins = []string{"*"}
+	anyone.Headers = []string{"*"}
+	cases := []struct {
+		name      string
+		cors      app.Cors
+		method    string
+		origin    string
+		request   string
+		status    int
+		allow     string
+		expose    string
+		headers   string
+		allowCred string
+	}{
+		{name: "same origin", cors: cors, method: http.MethodGet, status: http.StatusOK},
+		{name: "wildcard subdomain", cors: cors, method: http.MethodGet, origin: "https://api.Example.com", status: http.StatusOK, allow: "https://api.Example.com", expose: "X-Total"},
+		{name: "wildcard scheme mismatch", cors: cors, method: http.MethodGet, origin: "http://api.example.com", status: http.StatusOK},
+		{name: "wildcard suffix mismatch", cors: cors, method: http.MethodGet, origin: "https://example.com.evil.org", status: http.StatusOK},
+		{name: "exact origin", cors: cors, method: http.MethodGet, origin: "http://localhost:8080", status: http.StatusOK, allow: "http://localhost:8080", expose: "X-Total"},
+		{name: "preflight allowed", cors: cors, method: http.MethodOptions, origin: "https://www.example.com", request: "POST", status: http.StatusNoContent, allow: "https://www.example.com", headers: "Content-Type"},
+		{name: "preflight rejected", cors: cors, method: http.MethodOptions, origin: "https://evil.org", request: "POST", status: http.StatusForbidden},
+		{name: "any origin", cors: anyone, method: http.MethodOptions, origin: "https://evil.org", request: "GET", status: http.StatusNoContent, allow: "*", headers: "X-Token"},
+		{name: "credentials echo origin", cors: credentials, method: http.MethodGet, origin: "https://api.example.com", status: http.StatusOK, allow: "https://api.example.com", expose: "X-Total", allowCred: "true"},
+		{name: "credentials preflight", cors: credentials, method: http.MethodOptions, origin: "http://localhost:8080", request: "GET", status: http.StatusNoContent, allow: "http://localhost:8080", headers: "Content-Type", allowCred: "true"},
+		{name: "credentials any origin", cors: unsafe, method: http.MethodGet, origin: "https://evil.org", status: http.StatusOK},
+		{name: "credentials any origin preflight", cors: unsafe, method: http.MethodOptions, origin: "https://evil.org", request: "GET", status: http.StatusForbidden},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req := httptest.NewRequest(c.method, "/ping", nil)
+			if c.origin != "" {
+				req.Header.Set("Origin", c.origin)
+			}
+			if c.request != "" {
+				req.Header.Set("Access-Control-Request-Method", c.request)
+				req.Header.Set("Access-Control-Request-Headers", "X-Token")
+			}
+			res := httptest.NewRecorder()
+			corsApp(t, c.cors).ServeHTTP(res, req)
+			header := res.Header()
+			if res.Code != c.status {
+				t.Errorf("status %d", res.Code)
+			}
+			if value := header.Get("Access-Control-Allow-Origin"); value != c.allow {
+				t.Errorf("allow origin %q", value)
+			}
+			if value := header.Get("Access-Control-Expose-Headers"); value != c.expose {
+				t.Errorf("expose %q", value)
+			}
+			if value := header.Get("Access-Control-Allow-Headers"); value != c.headers {
+				t.Errorf("allow headers %q", value)
+			}
+			if value := header.Get("Access-Control-Allow-Credentials"); value != c.allowCred {
+				t.Errorf("allow credentials %q", value)
+			}
+			if c.status == http.StatusNoContent {
+				if header.Get("Access-Control-Allow-Methods") != "GET, POST" || header.Get("Access-Control-Max-Age") != "600" {
+					t.Errorf("preflight %v", header)
+				}
+			}
+			if header.Get("Vary") == "" {
+				t.Error("missing vary")
+			}
+		})
+	}
+}
+
+func TestCorsValidate(t *testing.T) {
+	global := app.GlobalDefault()
+	global.Service.Cors.Origins = []string{"*"}
+	global.Service.Cors.Credentials = true
+	if err := global.Validate(); err == nil || !strings.Contains(err.Error(), "service.cors.origins") {
+		t.Errorf("wildcard with credentials %v", err)
+	}
+}
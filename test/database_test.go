@@ -0,0 +1,73 @@
+package test
+
+import (
+	`errors`
+	`os`
+	`path/filepath`
+	`testing`
+	
+	`github.com/chaodoing/figure/app`
+	`github.com/chaodoing/figure/toolkit`
+)
+
+func TestDatabase(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("DIR", dir)
+	global := app.GlobalDefault()
+	global.MySQL.Replicas = []app.Replica{{Host: "10.0.0.1"}}
+	file := filepath.Join(dir, "app.xml")
+	if err := toolkit.SaveXML(global, file); err != nil {
+		t.Fatal(err)
+	}
+	// 命名连接只配置与默认连接不同的项
+	profile := filepath.Join(dir, "app.test.xml")
+	content := `<root><databases><alias>report</alias><mysql><host>10.0.0.9</host><name>report</name><logger><file>${DIR}/logs/report.log</file></logger></mysql></databases></root>`
+	if err := os.WriteFile(profile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("ENV", "test")
+	loaded, err := app.Load(file, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.Databases) != 1 || loaded.Databases[0].Alias != "report" {
+		t.Fatalf("databases %+v", loaded.Databases)
+	}
+	config, err := loaded.Database("report")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Host != "10.0.0.9" || config.Name != "report" || config.Port != global.MySQL.Port || config.Username != global.MySQL.Username {
+		t.Errorf("report %s:%d/%s@%s", config.Host, config.Port, config.Name, config.Username)
+	}
+	if config.Logger.File != filepath.Join(dir, "logs", "report.log") || config.Logger.Level != global.MySQL.Logger.Level {
+		t.Errorf("report logger %+v", config.Logger)
+	}
+	if len(config.Replicas) != 0 {
+		t.Errorf("replicas inherited %+v", config.Replicas)
+	}
+	if config, _ = loaded.Database(app.DatabaseDefault); config.Host != global.MySQL.Host {
+		t.Errorf("default %s", config.Host)
+	}
+	if _, err = loaded.DbNamed("missing"); err == nil {
+		t.Error("missing database opened")
+	}
+	// 名称必须唯一且不能使用保留名称
+	loaded.Databases = append(loaded.Databases, app.Database{Alias: "report"}, app.Database{Alias: app.DatabaseDefault}, app.Database{})
+	var invalid app.ValidationError
+	if err = loaded.Validate(); !errors.As(err, &invalid) {
+		t.Fatalf("unexpected error %v", err)
+	}
+	fields := make(map[string]bool)
+	for _, item := range invalid {
+		fields[item.Field] = true
+	}
+	for _, field := range []string{"databases.1.alias", "databases.2.alias", "databases.3.alias"} {
+		if !fields[field] {
+			t.Errorf("%s not reported", field)
+		}
+	}
+	if fields["databases.0.alias"] || fields["mysql.host"] {
+		t.Errorf("unexpected errors %v", invalid)
+	}
+}
@@ -0,0 +1,70 @@
+package test
+
+import (
+	`path/filepath`
+	`testing`
+	
+	`github.com/chaodoing/figure/app`
+)
+
+func TestDriver(t *testing.T) {
+	config := app.GlobalDefault().MySQL
+	if config.DSN() != config.Dialect() {
+		t.Errorf("mysql dsn %s", config.DSN())
+	}
+	config.Driver = app.DriverPostgres
+	config.Port = 5432
+	config.Password = "it's secret"
+	config.Timezone = "Asia/Shanghai"
+	config.TLS = "skip-verify"
+	config.Params = "application_name=figure"
+	expect := `TimeZone=Asia/Shanghai application_name=figure connect_timeout=10 dbname=dbName host=127.0.0.1 password='it\'s secret' port=5432 sslmode=require user=root`
+	if dsn := config.DSN(); dsn != expect {
+		t.Errorf("postgres dsn %s", dsn)
+	}
+	dir := t.TempDir()
+	t.Setenv("DIR", dir)
+	config.Driver = app.DriverSQLite
+	config.Name = "${DIR}/app.db"
+	config.Params = "_pragma=foreign_keys(1)"
+	if dsn := config.DSN(); dsn != filepath.Join(dir, "app.db")+"?_pragma=foreign_keys(1)" {
+		t.Errorf("sqlite dsn %s", dsn)
+	}
+}
+
+func TestSQLite(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("DIR", dir)
+	global := app.GlobalDefault()
+	global.MySQL.Driver = app.DriverSQLite
+	global.MySQL.Name = ":memory:"
+	global.MySQL.Logger.Console = false
+	global.MySQL.Logger.File = filepath.Join(dir, "mysql.log")
+	defer global.Close()
+	type Note struct {
+		ID    uint
+		Title string
+	}
+	db, err := global.Db()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = db.AutoMigrate(&Note{}); err != nil {
+		t.Fatal(err)
+	}
+	if err = db.Create(&Note{Title: "figure"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	// 内存数据库在同一个连接中保留数据
+	again, err := global.Db()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var note Note
+	if err = again.First(&note).Error; err != nil || note.Title != "figure" {
+		t.Errorf("note %+v %v", note, err)
+	}
+	if health := global.Health(); health.Probes[0].Status != app.StatusUp {
+		t.Errorf("health %+v", health.Probes[0])
+	}
+}
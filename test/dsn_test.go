@@ -0,0 +1,50 @@
+package test
+
+import (
+	`errors`
+	`os`
+	`path/filepath`
+	`testing`
+	`time`
+	
+	`github.com/chaodoing/figure/app`
+	`github.com/go-sql-driver/mysql`
+)
+
+func TestDialect(t *testing.T) {
+	config := app.GlobalDefault().MySQL
+	config.Password = "p@ss:word/"
+	config.Timezone = "Asia/Shanghai"
+	config.ReadTimeout, config.WriteTimeout = 30, 15
+	config.TLS = "skip-verify"
+	config.Collation = "utf8mb4_unicode_ci"
+	config.Params = "interpolateParams=true&sql_mode=TRADITIONAL"
+	dsn, err := mysql.ParseDSN(config.Dialect())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dsn.Passwd != config.Password || dsn.Addr != "127.0.0.1:3306" || dsn.DBName != "dbName" || !dsn.ParseTime {
+		t.Errorf("dsn %+v", dsn)
+	}
+	if dsn.Loc.String() != "Asia/Shanghai" || dsn.Timeout != 10*time.Second || dsn.ReadTimeout != 30*time.Second || dsn.WriteTimeout != 15*time.Second {
+		t.Errorf("loc %s timeout %s %s %s", dsn.Loc, dsn.Timeout, dsn.ReadTimeout, dsn.WriteTimeout)
+	}
+	if dsn.TLSConfig != "skip-verify" || dsn.Collation != "utf8mb4_unicode_ci" || !dsn.InterpolateParams || dsn.Params["sql_mode"] != "TRADITIONAL" || dsn.Params["charset"] != "utf8mb4" {
+		t.Errorf("params %s %s %v %v", dsn.TLSConfig, dsn.Collation, dsn.InterpolateParams, dsn.Params)
+	}
+	// 校验时区、TLS、参数和连接池
+	dir := t.TempDir()
+	t.Setenv("DIR", dir)
+	if err = os.MkdirAll(filepath.Join(dir, "resources", "template"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	global := app.GlobalDefault()
+	global.MySQL.Timezone = "Mars/Olympus"
+	global.MySQL.TLS = "maybe"
+	global.MySQL.Params = "a=%zz"
+	global.MySQL.Pool.MaxIdleConns = 200
+	var invalid app.ValidationError
+	if err = global.Validate(); !errors.As(err, &invalid) || len(invalid) != 4 {
+		t.Errorf("validate %v", err)
+	}
+}
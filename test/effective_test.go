@@ -0,0 +1,52 @@
+package test
+
+import (
+	`bytes`
+	`os`
+	`path/filepath`
+	`strings`
+	`testing`
+	
+	`github.com/chaodoing/figure/app`
+	`github.com/chaodoing/figure/cli`
+)
+
+func TestEffective(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("DIR", dir)
+	t.Setenv("FIGURE_SERVICE_PORT", "9300")
+	file := filepath.Join(dir, "app.json")
+	if err := os.WriteFile(file, []byte(`{"mysql":{"password":"p@ssw0rd"},"redis":{"auth":"secret"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	global, err := app.Load(file, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	effective := global.Effective()
+	if effective.Config.MySQL.Password != app.Redacted || global.MySQL.Password != "p@ssw0rd" {
+		t.Errorf("password %s", effective.Config.MySQL.Password)
+	}
+	settings := make(map[string]app.Setting)
+	for _, setting := range effective.Settings {
+		settings[setting.Name] = setting
+	}
+	expect := map[string]app.Setting{
+		"mysql.password": {Name: "mysql.password", Value: app.Redacted, Source: app.SourceFile, Env: "FIGURE_MYSQL_PASSWORD"},
+		"redis.auth":     {Name: "redis.auth", Value: app.Redacted, Source: app.SourceFile, Env: "FIGURE_REDIS_AUTH"},
+		"service.port":   {Name: "service.port", Value: "9300", Source: app.SourceEnv, Env: "FIGURE_SERVICE_PORT"},
+		"service.host":   {Name: "service.host", Value: "127.0.0.1", Source: app.SourceDefault, Env: "FIGURE_SERVICE_HOST"},
+	}
+	for name, setting := range expect {
+		if settings[name] != setting {
+			t.Errorf("%s %+v", name, settings[name])
+		}
+	}
+	var output bytes.Buffer
+	if err = (cli.Application{Output: &output}).Run([]string{"--config", file, "config", "print", "--sources"}); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(output.String(), "p@ssw0rd") || !strings.Contains(output.String(), "FIGURE_SERVICE_PORT") {
+		t.Error(output.String())
+	}
+}
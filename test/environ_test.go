@@ -0,0 +1,66 @@
+package test
+
+import (
+	`os`
+	`path/filepath`
+	`strings`
+	`testing`
+	
+	`github.com/chaodoing/figure/app`
+)
+
+func TestEnviron(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("DIR", dir)
+	file := filepath.Join(dir, "app.xml")
+	content := `<root><service><port>9100</port><resources><url>/static</url><dir>${DIR}/static</dir></resources></service></root>`
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".env.yml"), []byte("Redis:\n  Host: redis.local\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("FIGURE_MYSQL_PASSWORD", "secret")
+	t.Setenv("FIGURE_SERVICE_CORS_ORIGINS", "https://a.com, https://b.com")
+	t.Setenv("FIGURE_SERVICE_RESOURCES_1_URL", "/upload")
+	t.Setenv("FIGURE_SERVICE_RESOURCES_1_DIR", "${DIR}/upload")
+	global, err := app.Load(file, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if global.Service.Port != 9100 || global.Redis.Host != "redis.local" || global.MySQL.Password != "secret" {
+		t.Errorf("overlay %+v %+v %+v", global.Service.Port, global.Redis.Host, global.MySQL.Password)
+	}
+	if len(global.Service.Cors.Origins) != 2 || global.Service.Cors.Origins[1] != "https://b.com" {
+		t.Errorf("origins %v", global.Service.Cors.Origins)
+	}
+	if len(global.Service.Resources) != 2 || global.Service.Resources[1].Dir != filepath.Join(dir, "upload") {
+		t.Errorf("resources %+v", global.Service.Resources)
+	}
+	sources := map[string]string{
+		"service.host":            app.SourceDefault,
+		"service.port":            app.SourceFile,
+		"service.resources.0.dir": app.SourceFile,
+		"redis.host":              app.SourceEnvFile,
+		"mysql.password":          app.SourceEnv,
+		"service.cors.origins":    app.SourceEnv,
+		"service.resources.1.url": app.SourceEnv,
+	}
+	for name, source := range sources {
+		if value := global.Source(name); value != source {
+			t.Errorf("%s source %q, want %q", name, value, source)
+		}
+	}
+	t.Setenv("FIGURE_SERVICE_PORT", "port")
+	if _, err = app.Load(file, nil); err == nil {
+		t.Error("invalid FIGURE_SERVICE_PORT accepted")
+	}
+	// .env.yml 格式错误时返回错误，不静默忽略覆盖项
+	t.Setenv("FIGURE_SERVICE_PORT", "")
+	if err := os.WriteFile(filepath.Join(dir, ".env.yml"), []byte("Redis:\n  Host: [redis.local\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = app.Load(file, nil); err == nil || !strings.Contains(err.Error(), ".env.yml") {
+		t.Errorf("malformed .env.yml %v", err)
+	}
+}
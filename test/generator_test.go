@@ -0,0 +1,115 @@
+package test
+
+import (
+	`go/parser`
+	`go/token`
+	`path/filepath`
+	`strings`
+	`testing`
+	`time`
+	
+	`github.com/chaodoing/figure/app`
+	`github.com/chaodoing/figure/generator`
+	`github.com/chaodoing/figure/o`
+)
+
+func TestGenerator(t *testing.T) {
+	table := generator.Table{Name: "admin_user", Comment: "管理员\n账号", Columns: []generator.Column{
+		{Name: "id", DataType: "bigint", Type: "bigint(20) unsigned", Nullable: "NO", Key: "PRI", Extra: "auto_increment", Comment: "编号"},
+		{Name: "user_name", DataType: "varchar", Type: "varchar(64)", Nullable: "NO", Comment: "用户名"},
+		{Name: "role_id", DataType: "int", Type: "int(11)", Nullable: "YES"},
+		{Name: "birthday", DataType: "date", Type: "date", Nullable: "YES"},
+		{Name: "login_time", DataType: "time", Type: "time", Nullable: "NO"},
+		{Name: "created_at", DataType: "datetime", Type: "datetime", Nullable: "NO", Comment: `创建"时间"`},
+		{Name: "avatar", DataType: "blob", Type: "blob", Nullable: "YES"},
+	}}
+	content, err := table.Model("models")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 忽略 gofmt 对齐产生的空格
+	source := strings.Join(strings.Fields(string(content)), " ")
+	if _, err = parser.ParseFile(token.NewFileSet(), "admin_user.go", content, 0); err != nil {
+		t.Fatal(err, source)
+	}
+	for _, expect := range []string{
+		"// AdminUser 管理员 账号",
+		"ID uint64 `gorm:\"column:id;primaryKey;autoIncrement\" json:\"id\" xml:\"id\" yaml:\"ID\" comment:\"编号\"` // ID 编号",
+		"UserName string",
+		"RoleID *int32 `gorm:\"column:role_id\" json:\"role_id\" xml:\"roleID\" yaml:\"RoleID\"` // RoleID",
+		"Birthday *o.Date",
+		"LoginTime o.Time",
+		"CreatedAt o.Datetime `gorm:\"column:created_at\" json:\"created_at\" xml:\"createdAt\" yaml:\"CreatedAt\" comment:\"创建\\\"时间\\\"\"`",
+		"Avatar []byte",
+		"xml:\"adminUser\"",
+		"return \"admin_user\"",
+		"\"github.com/chaodoing/figure/o\"",
+	} {
+		if !strings.Contains(source, expect) {
+			t.Errorf("missing %s\n%s", expect, content)
+		}
+	}
+	if name := generator.Camel("api_url_2"); name != "APIURL2" {
+		t.Errorf("camel %s", name)
+	}
+}
+
+func TestScanTime(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("DIR", dir)
+	global := app.GlobalDefault()
+	global.MySQL.Driver = app.DriverSQLite
+	global.MySQL.Name = ":memory:"
+	global.MySQL.Logger.Console = false
+	global.MySQL.Logger.File = filepath.Join(dir, "mysql.log")
+	defer global.Close()
+	db, err := global.Db()
+	if err != nil {
+		t.Fatal(err)
+	}
+	type event struct {
+		ID        uint
+		Day       o.Date
+		At        o.Time
+		Finished  *o.Datetime
+	}
+	now := time.Date(2024, 5, 6, 7, 8, 9, 0, time.Local)
+	if err = db.AutoMigrate(&event{}); err != nil {
+		t.Fatal(err)
+	}
+	if err = db.Create(&event{ID: 1, Day: o.Date(now), At: o.Time(now)}).Error; err != nil {
+		t.Fatal(err)
+	}
+	var value event
+	if err = db.First(&value, 1).Error; err != nil {
+		t.Fatal(err)
+	}
+	day, _ := value.Day.MarshalText()
+	at, _ := value.At.MarshalText()
+	if string(day) != "2024-05-06" || string(at) != "07:08:09" || value.Finished != nil {
+		t.Errorf("event %s %s %v", day, at, value.Finished)
+	}
+}
+
+func TestGeneratorWrite(t *testing.T) {
+	dir := t.TempDir()
+	column := generator.Column{Name: "id", DataType: "int", Type: "int(11)", Nullable: "NO", Key: "PRI"}
+	var tables []generator.Table
+	for _, name := range []string{"order_test", "log_arm64", "Order-Item", "_migrations"} {
+		tables = append(tables, generator.Table{Name: name, Columns: []generator.Column{column}})
+	}
+	files, err := generator.Write(dir, "models", tables)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 文件名称不能被当作测试文件、带有构建约束或被构建忽略
+	expects := []string{"order_test_gen.go", "log_arm64_gen.go", "order_item_gen.go", "migrations_gen.go"}
+	for i, file := range files {
+		if name := filepath.Base(file); i >= len(expects) || name != expects[i] {
+			t.Errorf("file %d %s", i, name)
+		}
+	}
+	if len(files) != len(expects) {
+		t.Errorf("files %v", files)
+	}
+}
@@ -0,0 +1,46 @@
+package test
+
+import (
+	`os`
+	`path/filepath`
+	`reflect`
+	`testing`
+	
+	`github.com/chaodoing/figure/app`
+	`github.com/chaodoing/figure/toolkit`
+)
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("DIR", dir)
+	global := app.GlobalDefault()
+	global.Service.Port = 9527
+	savers := map[string]func(data interface{}, file string) error{
+		app.FormatXML:  toolkit.SaveXML,
+		app.FormatJSON: toolkit.SaveJSON,
+		app.FormatYAML: toolkit.SaveYAML,
+		app.FormatTOML: toolkit.SaveTOML,
+	}
+	for format, save := range savers {
+		// 不带扩展名的文件根据内容识别格式
+		for _, name := range []string{"app." + format, "app-" + format} {
+			file := filepath.Join(dir, name)
+			if err := save(global, file); err != nil {
+				t.Error(name, err)
+				continue
+			}
+			content, _ := os.ReadFile(file)
+			if value := app.Format(file, content); value != format {
+				t.Errorf("%s detected as %q", name, value)
+			}
+			loaded, err := app.Load(file, nil)
+			if err != nil {
+				t.Error(name, err)
+				continue
+			}
+			if loaded.Service.Port != global.Service.Port || !reflect.DeepEqual(loaded.Service.Cors, global.Service.Cors) || loaded.Redis != global.Redis {
+				t.Errorf("%s round trip mismatch", name)
+			}
+		}
+	}
+}
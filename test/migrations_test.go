@@ -0,0 +1,120 @@
+package test
+
+import (
+	`bytes`
+	`os`
+	`path/filepath`
+	`reflect`
+	`strings`
+	`testing`
+	`testing/fstest`
+	
+	`github.com/chaodoing/figure/app`
+	`github.com/chaodoing/figure/cli`
+	`github.com/chaodoing/figure/migrations`
+	`github.com/chaodoing/figure/toolkit`
+	`gorm.io/gorm`
+)
+
+func TestSplit(t *testing.T) {
+	content := "-- create; users\nCREATE TABLE `a;b` (name TEXT DEFAULT 'x;''y');\n/* ; */ INSERT INTO t VALUES (\"1;2\");;\n"
+	expect := []string{"CREATE TABLE `a;b` (name TEXT DEFAULT 'x;''y')", "INSERT INTO t VALUES (\"1;2\")"}
+	if statements := migrations.Split(content); !reflect.DeepEqual(statements, expect) {
+		t.Errorf("split %q", statements)
+	}
+}
+
+func TestMigrations(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("DIR", dir)
+	global := app.GlobalDefault()
+	global.MySQL.Driver = app.DriverSQLite
+	global.MySQL.Name = filepath.Join(dir, "app.db")
+	global.MySQL.Logger.Console = false
+	global.MySQL.Logger.File = filepath.Join(dir, "mysql.log")
+	defer global.Close()
+	db, err := global.Db()
+	if err != nil {
+		t.Fatal(err)
+	}
+	list, err := migrations.Load(fstest.MapFS{
+		"sql/001_users.up.sql":   {Data: []byte("CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT);\nINSERT INTO users (name) VALUES ('admin');")},
+		"sql/001_users.down.sql": {Data: []byte("DROP TABLE users;")},
+		"sql/README.md":          {Data: []byte("ignored")},
+	}, "sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// SQL 迁移与 Go 迁移按版本排序执行
+	list = append(list, migrations.Migration{
+		Version: "002",
+		Name:    "email",
+		Up: func(tx *gorm.DB) error {
+			return tx.Exec("ALTER TABLE users ADD COLUMN email TEXT").Error
+		},
+	})
+	m, err := migrations.New(db, list...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if applied, err := m.Up(0); err != nil || len(applied) != 2 {
+		t.Fatalf("up %v %v", applied, err)
+	}
+	if applied, err := m.Up(0); err != nil || len(applied) != 0 {
+		t.Errorf("up again %v %v", applied, err)
+	}
+	var count int64
+	if err = db.Table("users").Where("email IS NULL").Count(&count).Error; err != nil || count != 1 {
+		t.Errorf("users %d %v", count, err)
+	}
+	// 没有 Down 的迁移不能回滚
+	if _, err = m.Down(1); err == nil || !strings.Contains(err.Error(), "has no down") {
+		t.Errorf("down without down %v", err)
+	}
+	m, _ = migrations.New(db, list[0])
+	status, err := m.Status()
+	if err != nil || len(status) != 2 || !status[0].Applied || !status[1].Missing {
+		t.Errorf("status %+v %v", status, err)
+	}
+	if _, err = migrations.New(db, list[0], list[0]); err == nil {
+		t.Error("duplicate version accepted")
+	}
+}
+
+func TestMigrateCli(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("DIR", dir)
+	if err := os.MkdirAll(filepath.Join(dir, "resources", "template"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	global := app.GlobalDefault()
+	global.MySQL.Driver = app.DriverSQLite
+	global.MySQL.Name = "${DIR}/app.db"
+	global.MySQL.Logger.Console = false
+	file := filepath.Join(dir, "app.xml")
+	if err := toolkit.SaveXML(global, file); err != nil {
+		t.Fatal(err)
+	}
+	var output bytes.Buffer
+	application := cli.Application{Config: file, Output: &output}
+	if err := application.Run([]string{"migrate", "create", "Create Posts"}); err != nil {
+		t.Fatal(err)
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "migrations", "*_create_posts.up.sql"))
+	if len(files) != 1 {
+		t.Fatalf("created %v", files)
+	}
+	if err := os.WriteFile(files[0], []byte("CREATE TABLE posts (id INTEGER PRIMARY KEY);"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{{"migrate", "up"}, {"migrate", "status"}, {"migrate", "down"}} {
+		if err := application.Run(args); err != nil {
+			t.Fatal(args, err)
+		}
+	}
+	for _, expect := range []string{"1 migrations applied", "applied", "1 migrations reverted"} {
+		if !strings.Contains(output.String(), expect) {
+			t.Errorf("output missing %q:\n%s", expect, output.String())
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package test
+
+import (
+	`encoding/json`
+	`encoding/xml`
+	`reflect`
+	`testing`
+	
+	`github.com/chaodoing/figure/app`
+	`github.com/kataras/iris/v12`
+	`gopkg.in/yaml.v2`
+)
+
+// admin 测试使用的模块配置
+type admin struct {
+	Route string `json:"route" xml:"route" yaml:"Route"`
+	Size  int    `json:"size" xml:"size" yaml:"Size"`
+}
+
+// adminModule 测试使用的模块
+type adminModule struct {
+	config admin
+}
+
+func (m *adminModule) Name() string                                            { return "admin" }
+func (m *adminModule) Config() interface{}                                     { return &m.config }
+func (m *adminModule) Depends() []string                                       { return nil }
+func (m *adminModule) Register(app *iris.Application, global app.Global) error { return nil }
+func (m *adminModule) Migrate(global app.Global) error                         { return nil }
+func (m *adminModule) Shutdown(global app.Global) error                        { return nil }
+
+func TestModules(t *testing.T) {
+	module := &adminModule{config: admin{Route: "/admin", Size: 20}}
+	global := app.GlobalDefault().WithModules(module)
+	codecs := []struct {
+		format    string
+		marshal   func(value interface{}) ([]byte, error)
+		unmarshal func(content []byte, value interface{}) error
+	}{
+		{"json", json.Marshal, json.Unmarshal},
+		{"xml", xml.Marshal, xml.Unmarshal},
+		{"yaml", yaml.Marshal, yaml.Unmarshal},
+	}
+	for _, codec := range codecs {
+		content, err := codec.marshal(global)
+		if err != nil {
+			t.Error(codec.format, err)
+			continue
+		}
+		var loaded app.Global
+		if err = codec.unmarshal(content, &loaded); err != nil {
+			t.Error(codec.format, err)
+			continue
+		}
+		var config admin
+		ok, err := loaded.Modules.Decode(module.Name(), &config)
+		if err != nil || !ok || config != module.config {
+			t.Errorf("%s decode %+v %v %v", codec.format, config, ok, err)
+		}
+	}
+}
+
+func TestModuleOrder(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("DIR", dir)
+	boot, err := app.New(bootConfig(t, dir, freePort(t), pong(t), ""), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer boot.Global.Close()
+	type node struct {
+		name    string
+		depends []string
+	}
+	cases := []struct {
+		name    string
+		modules []node
+		order   []string
+		err     string
+	}{
+		{name: "registration order", modules: []node{{"x", nil}, {"y", nil}}, order: []string{"x", "y"}},
+		{name: "dependencies first", modules: []node{{"c", []string{"b"}}, {"a", nil}, {"b", []string{"a"}}}, order: []string{"a", "b", "c"}},
+		{name: "diamond", modules: []node{{"d", []string{"b", "c"}}, {"c", []string{"a"}}, {"b", []string{"a"}}, {"a", nil}}, order: []string{"a", "b", "c", "d"}},
+		{name: "missing dependency", modules: []node{{"a", []string{"auth"}}}, err: "module a depends on unregistered module auth"},
+		{name: "cycle", modules: []node{{"a", []string{"b"}}, {"b", []string{"c"}}, {"c", []string{"a"}}}, err: "module dependency cycle: [a b c a]"},
+		{name: "self dependency", modules: []node{{"a", []string{"a"}}}, err: "module dependency cycle: [a a]"},
+		{name: "duplicate", modules: []node{{"a", nil}, {"a", nil}}, err: "module a registered more than once"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			record := &recorder{}
+			modules := make([]app.Module, 0, len(c.modules))
+			for _, value := range c.modules {
+				modules = append(modules, &lifecycle{name: value.name, depends: value.depends, record: record})
+			}
+			err := boot.Use(modules...).Migrate()
+			if c.err != "" {
+				if err == nil || err.Error() != c.err {
+					t.Errorf("error %v", err)
+				}
+				if events := record.list(); len(events) != 0 {
+					t.Errorf("migrated %v", events)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			order := make([]string, 0, len(c.order))
+			for _, name := range c.order {
+				order = append(order, "migrate "+name)
+			}
+			if events := record.list(); !reflect.DeepEqual(events, order) {
+				t.Errorf("order %v", events)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package test
+
+import (
+	`bytes`
+	`os`
+	`path/filepath`
+	`strings`
+	`testing`
+	
+	`github.com/chaodoing/figure/app`
+	`github.com/chaodoing/figure/cli`
+)
+
+func TestProfile(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("DIR", dir)
+	t.Setenv("ENV", "testing")
+	files := map[string]string{
+		"app.json":         `{"service":{"host":"0.0.0.0","resources":[{"url":"/static","dir":"/srv/static"}]},"mysql":{"name":"figure"}}`,
+		"app.testing.json": `{"service":{"port":9200,"resources":[{"url":"/static","dir":"/tmp/static"},{"url":"/upload","dir":"/tmp/upload"}]}}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	file := filepath.Join(dir, "app.json")
+	if profile := app.Profile(file); profile != filepath.Join(dir, "app.testing.json") {
+		t.Errorf("profile %s", profile)
+	}
+	global, err := app.Load(file, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if global.Service.Host != "0.0.0.0" || global.Service.Port != 9200 || global.MySQL.Name != "figure" {
+		t.Errorf("merge %s %d %s", global.Service.Host, global.Service.Port, global.MySQL.Name)
+	}
+	resources := global.Service.Resources
+	if len(resources) != 2 || resources[0].Dir != "/tmp/static" || resources[1].Url != "/upload" {
+		t.Errorf("resources %+v", resources)
+	}
+	if global.Source("service.port") != app.SourceProfile || global.Source("service.host") != app.SourceFile {
+		t.Errorf("sources %s %s", global.Source("service.port"), global.Source("service.host"))
+	}
+	var output bytes.Buffer
+	if err = (cli.Application{Output: &output}).Run([]string{"--config", file, "config", "print", "--format", "json"}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(output.String(), `"port": 9200`) || !strings.Contains(output.String(), app.Redacted) {
+		t.Error(output.String())
+	}
+}
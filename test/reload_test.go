@@ -0,0 +1,43 @@
+package test
+
+import (
+	`os`
+	`path/filepath`
+	`reflect`
+	`testing`
+	
+	`github.com/chaodoing/figure/app`
+)
+
+func TestReload(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("DIR", dir)
+	file := filepath.Join(dir, "app.json")
+	if err := os.WriteFile(file, []byte(`{"service":{"port":8080,"log":{"level":"info"}}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	previous, err := app.Load(file, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(file, []byte(`{"service":{"port":9090,"log":{"level":"debug"},"cors":{"max_age":600}},"redis":{"ttl":60}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := app.Load(file, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	current, ignored := previous.Reload(loaded)
+	if current.Service.Port != 8080 {
+		t.Errorf("port reloaded %d", current.Service.Port)
+	}
+	if current.Service.Log.Level != "debug" || current.Service.Cors.MaxAge != 600 || current.Redis.TTL != 60 {
+		t.Errorf("reload %s %d %d", current.Service.Log.Level, current.Service.Cors.MaxAge, current.Redis.TTL)
+	}
+	if !reflect.DeepEqual(ignored, []string{"service.port"}) {
+		t.Errorf("ignored %v", ignored)
+	}
+	if !app.Reloadable("service.cors.max_age") || app.Reloadable("service.cross_domain") {
+		t.Error("reloadable")
+	}
+}
@@ -0,0 +1,120 @@
+package test
+
+import (
+	`context`
+	`errors`
+	`path/filepath`
+	`testing`
+	`time`
+	
+	`github.com/chaodoing/figure/app`
+	`github.com/glebarez/sqlite`
+	`gorm.io/gorm`
+)
+
+// pool 用于测试副本选择的连接池，down 为 true 时检查失败
+type pool struct {
+	gorm.ConnPool
+	name string
+	down bool
+}
+
+func (p *pool) PingContext(ctx context.Context) error {
+	if p.down {
+		return errors.New(p.name + " is down")
+	}
+	return nil
+}
+
+// resolve 多次选择并统计每个连接池被选择的次数
+func resolve(policy interface {
+	Resolve([]gorm.ConnPool) gorm.ConnPool
+}, pools []gorm.ConnPool, times int) map[string]int {
+	count := make(map[string]int)
+	for i := 0; i < times; i++ {
+		count[policy.Resolve(pools).(*pool).name]++
+	}
+	return count
+}
+
+func TestReplicaPolicy(t *testing.T) {
+	first, second, primary := &pool{name: "first"}, &pool{name: "second"}, &pool{name: "primary"}
+	pools := []gorm.ConnPool{first, second}
+	replicas := []app.Replica{{Host: "10.0.0.1", Weight: 3}, {Host: "10.0.0.2"}}
+	if count := resolve(app.ReplicaPolicy(app.PolicyRoundRobin, replicas, primary), pools, 10); count["first"] != 5 || count["second"] != 5 {
+		t.Errorf("round-robin %v", count)
+	}
+	if count := resolve(app.ReplicaPolicy(app.PolicyWeighted, replicas, primary), pools, 8); count["first"] != 6 || count["second"] != 2 {
+		t.Errorf("weighted %v", count)
+	}
+	// 检查失败的副本不再被选择，全部失败时使用主库
+	first.down, second.down = true, true
+	policy := app.ReplicaPolicy(app.PolicyRoundRobin, replicas, primary)
+	resolve(policy, pools, 1)
+	time.Sleep(50 * time.Millisecond)
+	if count := resolve(policy, pools, 4); count["primary"] != 4 {
+		t.Errorf("all down %v", count)
+	}
+}
+
+// item 用于区分主库和副本的数据
+type item struct {
+	ID   uint
+	Name string
+}
+
+func TestReplicaSQLite(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("DIR", dir)
+	replica := filepath.Join(dir, "replica.db")
+	for file, name := range map[string]string{filepath.Join(dir, "app.db"): "primary", replica: "replica"} {
+		db, err := gorm.Open(sqlite.Open(file), &gorm.Config{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = db.AutoMigrate(&item{}); err != nil {
+			t.Fatal(err)
+		}
+		if err = db.Create(&item{Name: name}).Error; err != nil {
+			t.Fatal(err)
+		}
+		if sqlDB, err := db.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	}
+	global, err := app.Load(bootConfig(t, dir, freePort(t), pong(t), ""), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer global.Close()
+	global.MySQL.Replicas = []app.Replica{{Host: replica}}
+	db, err := global.Db()
+	if err != nil {
+		t.Fatal(err)
+	}
+	name := func(db *gorm.DB) string {
+		var value item
+		if err := db.First(&value).Error; err != nil {
+			t.Fatal(err)
+		}
+		return value.Name
+	}
+	// 读取使用副本，Primary 强制使用主库
+	if value := name(db); value != "replica" {
+		t.Errorf("read from %s", value)
+	}
+	if value := name(app.Primary(db)); value != "primary" {
+		t.Errorf("primary read from %s", value)
+	}
+	// 写入使用主库
+	if err = db.Create(&item{Name: "written"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	var count int64
+	if err = app.Primary(db).Model(&item{}).Where("name = ?", "written").Count(&count).Error; err != nil || count != 1 {
+		t.Errorf("write to primary %d %v", count, err)
+	}
+	if err = db.Model(&item{}).Where("name = ?", "written").Count(&count).Error; err != nil || count != 0 {
+		t.Errorf("write to replica %d %v", count, err)
+	}
+}
@@ -0,0 +1,205 @@
+package test
+
+import (
+	`encoding/json`
+	`errors`
+	`net/http`
+	`net/http/httptest`
+	`path/filepath`
+	`strings`
+	`testing`
+	
+	`github.com/chaodoing/figure/app`
+	`github.com/chaodoing/figure/resource`
+	`github.com/kataras/iris/v12`
+	`github.com/kataras/iris/v12/mvc`
+	`gorm.io/gorm`
+)
+
+type (
+	// article 资源控制器测试使用的模型
+	article struct {
+		ID    uint   `json:"id"`
+		Title string `json:"title"`
+	}
+	
+	// articleController 嵌入 Resource 的控制器
+	articleController struct {
+		resource.Resource[article]
+	}
+	
+	// reply 资源控制器的 JSON 响应
+	reply struct {
+		Page    int             `json:"page"`
+		Total   int             `json:"total"`
+		Size    int             `json:"size"`
+		Code    int             `json:"code"`
+		Message string          `json:"message"`
+		Data    json.RawMessage `json:"data"`
+	}
+)
+
+// Validate 标题不能为空
+func (a *article) Validate() error {
+	if strings.TrimSpace(a.Title) == "" {
+		return errors.New("title is required")
+	}
+	return nil
+}
+
+func TestResource(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("DIR", dir)
+	global := app.GlobalDefault()
+	global.MySQL.Driver = app.DriverSQLite
+	global.MySQL.Name = ":memory:"
+	global.MySQL.Logger.Console = false
+	global.MySQL.Logger.File = filepath.Join(dir, "mysql.log")
+	defer global.Close()
+	db, err := global.Db()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = db.AutoMigrate(&article{}); err != nil {
+		t.Fatal(err)
+	}
+	for _, title := range []string{"a", "b", "c", "d", "e"} {
+		if err = db.Create(&article{Title: title}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	application := iris.New()
+	m := mvc.New(application.Party("/articles"))
+	m.Register(func(ctx iris.Context) app.Global {
+		return global
+	})
+	m.Handle(&articleController{resource.New[article]().Size(2).Maximum(3)})
+	if err = application.Build(); err != nil {
+		t.Fatal(err)
+	}
+	request := func(method, target, body string, status int) (value reply) {
+		t.Helper()
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("Content-Type", "application/json")
+		res := httptest.NewRecorder()
+		application.ServeHTTP(res, req)
+		if res.Code != status {
+			t.Fatalf("%s %s status %d %s", method, target, res.Code, res.Body)
+		}
+		if err := json.Unmarshal(res.Body.Bytes(), &value); err != nil {
+			t.Fatalf("%s %s %v %s", method, target, err, res.Body)
+		}
+		return
+	}
+	
+	// 分页
+	list := request(http.MethodGet, "/articles?page=2", "", http.StatusOK)
+	if list.Page != 2 || list.Size != 2 || list.Total != 5 || string(list.Data) != `[{"id":3,"title":"c"},{"id":4,"title":"d"}]` {
+		t.Errorf("list %+v %s", list, list.Data)
+	}
+	if list = request(http.MethodGet, "/articles?size=50", "", http.StatusOK); list.Size != 3 {
+		t.Errorf("maximum %+v", list)
+	}
+	// 详情
+	if show := request(http.MethodGet, "/articles/2", "", http.StatusOK); string(show.Data) != `{"id":2,"title":"b"}` {
+		t.Errorf("show %s", show.Data)
+	}
+	if missing := request(http.MethodGet, "/articles/9", "", http.StatusNotFound); missing.Code != resource.CodeNotFound {
+		t.Errorf("missing %+v", missing)
+	}
+	// 创建时忽略请求体中的主键，校验失败返回 400
+	if created := request(http.MethodPost, "/articles", `{"id":1,"title":"f"}`, http.StatusCreated); string(created.Data) != `{"id":6,"title":"f"}` {
+		t.Errorf("create %s", created.Data)
+	}
+	if invalid := request(http.MethodPost, "/articles", `{"title":" "}`, http.StatusBadRequest); invalid.Code != resource.CodeInvalid {
+		t.Errorf("invalid %+v", invalid)
+	}
+	request(http.MethodPost, "/articles", `{"title":`, http.StatusBadRequest)
+	// 更新时主键以路径为准
+	if updated := request(http.MethodPut, "/articles/3", `{"id":1,"title":"C"}`, http.StatusOK); string(updated.Data) != `{"id":3,"title":"C"}` {
+		t.Errorf("update %s", updated.Data)
+	}
+	var first article
+	if db.First(&first, 1); first.Title != "a" {
+		t.Errorf("first %+v", first)
+	}
+	// 删除
+	request(http.MethodDelete, "/articles/3", "", http.StatusOK)
+	request(http.MethodDelete, "/articles/3", "", http.StatusNotFound)
+	var count int64
+	if db.Model(&article{}).Count(&count); count != 5 {
+		t.Errorf("count %d", count)
+	}
+}
+
+func TestResourceScope(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("DIR", dir)
+	global := app.GlobalDefault()
+	global.MySQL.Driver = app.DriverSQLite
+	global.MySQL.Name = ":memory:"
+	global.MySQL.Logger.Console = false
+	global.MySQL.Logger.File = filepath.Join(dir, "mysql.log")
+	defer global.Close()
+	db, err := global.Db()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = db.AutoMigrate(&article{}); err != nil {
+		t.Fatal(err)
+	}
+	for _, title := range []string{"s1", "s2", "x", "s3"} {
+		if err = db.Create(&article{Title: title}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	application := iris.New()
+	m := mvc.New(application.Party("/articles"))
+	m.Register(func(ctx iris.Context) app.Global {
+		return global
+	})
+	// 只能访问标题以 s 开头的记录
+	m.Handle(&articleController{resource.New[article]().Size(2).Scope(func(ctx iris.Context, db *gorm.DB) *gorm.DB {
+		return db.Where("title LIKE ?", "s%")
+	})})
+	if err = application.Build(); err != nil {
+		t.Fatal(err)
+	}
+	request := func(method, target, body string, status int) (value reply) {
+		t.Helper()
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("Content-Type", "application/json")
+		res := httptest.NewRecorder()
+		application.ServeHTTP(res, req)
+		if res.Code != status {
+			t.Fatalf("%s %s status %d %s", method, target, res.Code, res.Body)
+		}
+		if err := json.Unmarshal(res.Body.Bytes(), &value); err != nil {
+			t.Fatalf("%s %s %v %s", method, target, err, res.Body)
+		}
+		return
+	}
+	
+	// 统计和查询分别使用新的语句
+	list := request(http.MethodGet, "/articles?page=2", "", http.StatusOK)
+	if list.Total != 3 || string(list.Data) != `[{"id":4,"title":"s3"}]` {
+		t.Errorf("list %+v %s", list, list.Data)
+	}
+	// 查询之后的更新和删除不会带上查询语句的条件
+	if updated := request(http.MethodPut, "/articles/1", `{"title":"s1b"}`, http.StatusOK); string(updated.Data) != `{"id":1,"title":"s1b"}` {
+		t.Errorf("update %s", updated.Data)
+	}
+	var first article
+	if db.First(&first, 1); first.Title != "s1b" {
+		t.Errorf("first %+v", first)
+	}
+	request(http.MethodPut, "/articles/3", `{"title":"s4"}`, http.StatusNotFound)
+	request(http.MethodDelete, "/articles/2", "", http.StatusOK)
+	request(http.MethodDelete, "/articles/3", "", http.StatusNotFound)
+	var titles []string
+	if db.Model(&article{}).Order("id").Pluck("title", &titles); strings.Join(titles, ",") != "s1b,x,s3" {
+		t.Errorf("titles %v", titles)
+	}
+}
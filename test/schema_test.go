@@ -0,0 +1,80 @@
+package test
+
+import (
+	`bytes`
+	`encoding/json`
+	`encoding/xml`
+	`os`
+	`path/filepath`
+	`testing`
+	
+	`github.com/chaodoing/figure/app`
+	`github.com/chaodoing/figure/cli`
+)
+
+// property 读取 JSON Schema 中以 properties 嵌套的属性
+func property(node map[string]interface{}, names ...string) map[string]interface{} {
+	for _, name := range names {
+		properties, _ := node["properties"].(map[string]interface{})
+		if node, _ = properties[name].(map[string]interface{}); node == nil {
+			return nil
+		}
+	}
+	return node
+}
+
+func TestSchema(t *testing.T) {
+	global := app.GlobalDefault().WithModules(&adminModule{config: admin{Route: "/admin", Size: 20}})
+	for format, names := range map[string][]string{
+		app.FormatJSON: {"service", "port"},
+		app.FormatYAML: {"Service", "Port"},
+	} {
+		content, err := global.JSONSchema(format)
+		if err != nil {
+			t.Fatal(format, err)
+		}
+		var schema map[string]interface{}
+		if err = json.Unmarshal(content, &schema); err != nil {
+			t.Fatal(format, err)
+		}
+		port := property(schema, names...)
+		if port == nil || port["type"] != "integer" || port["default"] != float64(9000) || port["maximum"] != float64(65535) || port["description"] != "监听端口" {
+			t.Errorf("%s port %v", format, port)
+		}
+	}
+	content, err := global.JSONSchema(app.FormatJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var schema map[string]interface{}
+	_ = json.Unmarshal(content, &schema)
+	if level := property(schema, "service", "log", "level"); level == nil || len(level["enum"].([]interface{})) != 6 {
+		t.Errorf("level %v", level)
+	}
+	if route := property(schema, "modules", "admin", "route"); route == nil || route["default"] != "/admin" {
+		t.Errorf("module %v", route)
+	}
+	if _, err = global.JSONSchema("ini"); err == nil {
+		t.Error("unsupported format accepted")
+	}
+	if content, err = global.XSD(); err != nil {
+		t.Fatal(err)
+	}
+	if err = xml.Unmarshal(content, new(struct{})); err != nil {
+		t.Error(err)
+	}
+	if !bytes.Contains(content, []byte(`<xs:element name="port" type="xs:unsignedShort" default="9000">`)) {
+		t.Error(string(content))
+	}
+	// 命令行输出到文件
+	dir := t.TempDir()
+	var output bytes.Buffer
+	application := cli.Application{Output: &output}
+	file := filepath.Join(dir, "schema", "app.xsd")
+	if err = application.Run([]string{"--config", filepath.Join(dir, "app.xml"), "config", "schema", "--output", file}); err != nil {
+		t.Fatal(err)
+	}
+	if content, err = os.ReadFile(file); err != nil || !bytes.HasPrefix(content, []byte(xml.Header)) {
+		t.Error(err, string(content))
+	}
+}
@@ -0,0 +1,136 @@
+package test
+
+import (
+	`encoding/json`
+	`errors`
+	`os`
+	`path/filepath`
+	`strings`
+	`testing`
+	
+	`github.com/chaodoing/figure/app`
+	`github.com/chaodoing/figure/encrypt/aes`
+)
+
+// master 和 another 为测试使用的主密钥
+const (
+	master  = "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="
+	another = "ZmVkY2JhOTg3NjU0MzIxMGZlZGNiYTk4NzY1NDMyMTA="
+)
+
+func TestSecret(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("DIR", dir)
+	t.Setenv(app.MasterKeyEnv, master)
+	password, err := app.Encrypt("mysql-secret", master)
+	if err != nil || !app.Encrypted(password) {
+		t.Fatalf("unexpected format %s %v", password, err)
+	}
+	// 随机 nonce，相同的明文每次得到不同的密文
+	if again, _ := app.Encrypt("mysql-secret", master); again == password {
+		t.Error("ciphertext reused")
+	}
+	// 篡改密文时认证失败
+	tampered := []byte(password)
+	if middle := len(tampered) / 2; tampered[middle] == 'A' {
+		tampered[middle] = 'B'
+	} else {
+		tampered[middle] = 'A'
+	}
+	if _, err = app.Decrypt(string(tampered), master); !errors.Is(err, aes.ErrAuthentication) {
+		t.Errorf("tampered value %v", err)
+	}
+	file := filepath.Join(dir, "app.yaml")
+	if err := os.WriteFile(file, []byte("MySQL:\n  Password: "+password+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	global, err := app.Load(file, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if global.MySQL.Password != "mysql-secret" {
+		t.Errorf("password %q", global.MySQL.Password)
+	}
+	redacted := global.Redact()
+	if redacted.MySQL.Password != password || redacted.Redis.Auth != app.Redacted || global.Redis.Auth == app.Redacted {
+		t.Errorf("redact %q %q", redacted.MySQL.Password, redacted.Redis.Auth)
+	}
+	t.Setenv(app.MasterKeyEnv, another)
+	if _, err = app.Load(file, nil); !errors.Is(err, aes.ErrAuthentication) || !strings.Contains(err.Error(), "mysql.password") {
+		t.Errorf("wrong key: %v", err)
+	}
+	// 主密钥必须是 Base64 编码的 32 字节随机数，不接受口令
+	if _, err = app.Encrypt("mysql-secret", "figure-master-key"); !errors.Is(err, app.ErrMasterKeyFormat) {
+		t.Errorf("passphrase accepted: %v", err)
+	}
+	t.Setenv(app.MasterKeyEnv, "figure-master-key")
+	if _, err = app.Load(file, nil); !errors.Is(err, app.ErrMasterKeyFormat) {
+		t.Errorf("passphrase loaded: %v", err)
+	}
+	generated, err := app.GenerateMasterKey()
+	if err != nil || generated == master {
+		t.Fatal(generated, err)
+	}
+	if _, err = app.Encrypt("mysql-secret", generated); err != nil {
+		t.Error(err)
+	}
+	t.Setenv(app.MasterKeyEnv, "")
+	if _, err = app.Load(file, nil); err == nil {
+		t.Error("missing master key accepted")
+	}
+}
+
+// mailer 带有敏感字段的模块配置
+type mailer struct {
+	Host     string `json:"host" xml:"host" yaml:"Host"`
+	Password string `json:"password" xml:"password" yaml:"Password" secret:"true"`
+}
+
+func TestModuleSecret(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("DIR", dir)
+	t.Setenv(app.MasterKeyEnv, master)
+	password, err := app.Encrypt("smtp <&> secret", master)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"app.yaml": "Modules:\n  mailer:\n    Host: smtp.local\n    Password: " + password + "\n",
+		"app.json": `{"modules": {"mailer": {"host": "smtp.local", "password": "` + password + `"}}}`,
+		"app.xml":  "<root><modules><mailer><host>smtp.local</host><password>" + password + "</password></mailer></modules></root>",
+	}
+	for name, content := range files {
+		file := filepath.Join(dir, name)
+		if err = os.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		global, err := app.Load(file, nil)
+		if err != nil {
+			t.Fatal(name, err)
+		}
+		var config mailer
+		if ok, err := global.Modules.Decode("mailer", &config); !ok || err != nil || config.Host != "smtp.local" || config.Password != "smtp <&> secret" {
+			t.Errorf("%s decode %+v %v", name, config, err)
+		}
+		// 脱敏后恢复为原始的加密值
+		output, err := json.Marshal(global.Effective())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(output), "smtp <") || !strings.Contains(string(output), password) {
+			t.Errorf("%s effective %s", name, output)
+		}
+	}
+	t.Setenv(app.MasterKeyEnv, another)
+	if _, err = app.Load(filepath.Join(dir, "app.yaml"), nil); !errors.Is(err, aes.ErrAuthentication) || !strings.Contains(err.Error(), "modules.mailer") {
+		t.Errorf("wrong key: %v", err)
+	}
+	// 由 Set 设置的配置值按照 secret 标签脱敏，不修改原配置
+	global := app.GlobalDefault()
+	value := &mailer{Host: "smtp.local", Password: "plain"}
+	global.Modules.Set("mailer", value)
+	var config mailer
+	if _, err = global.Redact().Modules.Decode("mailer", &config); err != nil || config.Password != app.Redacted || config.Host != "smtp.local" || value.Password != "plain" {
+		t.Errorf("redact %+v %+v %v", config, value, err)
+	}
+}
@@ -0,0 +1,120 @@
+package test
+
+import (
+	`os`
+	`path/filepath`
+	`testing`
+	`time`
+	
+	`github.com/chaodoing/figure/app`
+	`github.com/chaodoing/figure/seeds`
+)
+
+type (
+	// role 种子数据测试使用的角色
+	role struct {
+		ID   uint
+		Name string
+	}
+	
+	// member 种子数据测试使用的用户，依赖角色
+	member struct {
+		ID        uint
+		UUID      string
+		Name      string
+		RoleID    uint
+		CreatedAt time.Time
+	}
+)
+
+// seedFiles 写入种子文件
+func seedFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		file := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestSeeds(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("DIR", dir)
+	global := app.GlobalDefault()
+	global.MySQL.Driver = app.DriverSQLite
+	global.MySQL.Name = ":memory:"
+	global.MySQL.Logger.Console = false
+	global.MySQL.Logger.File = filepath.Join(dir, "mysql.log")
+	defer global.Close()
+	db, err := global.Db()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = db.AutoMigrate(&role{}, &member{}); err != nil {
+		t.Fatal(err)
+	}
+	if err = db.Exec("CREATE TABLE tags (id INTEGER PRIMARY KEY, label TEXT)").Error; err != nil {
+		t.Fatal(err)
+	}
+	seedFiles(t, filepath.Join(dir, "seeds"), map[string]string{
+		"members.yml":      "depends: [roles]\nrows:\n  - id: 1\n    uuid: \"{{ uuid }}\"\n    name: admin\n    role_id: 1\n    created_at: \"{{ days -7 }}\"\n",
+		"roles.json":       `{"rows": [{"id": 1, "name": "root"}, {"id": 2, "name": "guest"}]}`,
+		"tags.yml":         "rows:\n  - id: 1\n    label: '{{ env \"APP_TAG\" }}'\n",
+		"README.md":        "ignored",
+		"test/members.yml": "depends: [roles]\nrows:\n  - id: 1\n    name: tester\n    role_id: 2\n  - id: 2\n    name: \"{{ uuid }}\"\n    role_id: 2\n",
+	})
+	t.Setenv("APP_TAG", "seed")
+	seeder, err := seeds.New(db).Model(&role{}, &member{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	base, err := seeder.Load("${DIR}/seeds", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixtures, err := base.Fixtures()
+	if err != nil || len(fixtures) != 3 || fixtures[0].Table != "roles" || fixtures[1].Table != "members" {
+		t.Fatalf("fixtures %+v %v", fixtures, err)
+	}
+	if err = base.Seed(); err != nil {
+		t.Fatal(err)
+	}
+	var admin member
+	if err = db.First(&admin, 1).Error; err != nil || len(admin.UUID) != 36 || time.Since(admin.CreatedAt) < 6*24*time.Hour {
+		t.Errorf("admin %+v %v", admin, err)
+	}
+	var label string
+	if db.Raw("SELECT label FROM tags WHERE id = 1").Scan(&label); label != "seed" {
+		t.Errorf("tag %q", label)
+	}
+	// 环境种子数据替换同名文件，重新加载时先清空
+	staged, err := seeder.Load("${DIR}/seeds", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = staged.Seed(); err == nil {
+		t.Error("seed duplicate rows")
+	}
+	if err = staged.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	var members []member
+	if err = db.Order("id").Find(&members).Error; err != nil || len(members) != 2 || members[0].Name != "tester" || members[1].RoleID != 2 {
+		t.Errorf("members %+v %v", members, err)
+	}
+	if err = staged.Truncate(); err != nil {
+		t.Fatal(err)
+	}
+	var count int64
+	if db.Model(&role{}).Count(&count); count != 0 {
+		t.Errorf("roles %d", count)
+	}
+	// 依赖不存在的表
+	seedFiles(t, filepath.Join(dir, "seeds"), map[string]string{"posts.yml": "depends: [authors]\n"})
+	if broken, _ := seeder.Load("${DIR}/seeds", ""); broken.Seed() == nil {
+		t.Error("unknown dependency accepted")
+	}
+}
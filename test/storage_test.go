@@ -0,0 +1,83 @@
+package test
+
+import (
+	`errors`
+	`io`
+	`io/fs`
+	`os`
+	`strings`
+	`testing`
+	
+	`github.com/chaodoing/figure/app`
+)
+
+// testStorage 依次测试存储的写入、读取、查看、列出和删除。
+func testStorage(t *testing.T, storage app.Storage) {
+	key := "2024/01/02/hello.txt"
+	err := storage.Put(key, strings.NewReader("hello figure"), 12, "text/plain")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	reader, err := storage.Get(key)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	content, err := io.ReadAll(reader)
+	_ = reader.Close()
+	if err != nil || string(content) != "hello figure" {
+		t.Errorf("get %q %v", content, err)
+	}
+	object, err := storage.Stat(key)
+	if err != nil || object.Size != 12 {
+		t.Errorf("stat %+v %v", object, err)
+	}
+	objects, err := storage.List("2024/01/")
+	if err != nil || len(objects) != 1 || objects[0].Key != key {
+		t.Errorf("list %+v %v", objects, err)
+	}
+	t.Log(storage.Url(key))
+	t.Log(storage.Presign(key, 0))
+	if err = storage.Delete(key); err != nil {
+		t.Error(err)
+	}
+	if _, err = storage.Stat(key); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("stat after delete %v", err)
+	}
+}
+
+func TestLocalStorage(t *testing.T) {
+	upload := app.GlobalDefault().Service.Upload
+	upload.Resource.Dir = t.TempDir()
+	local, err := app.NewStorage(upload)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	testStorage(t, local)
+}
+
+// TestS3Storage 需要可用的 MinIO，例如:
+// MINIO_ENDPOINT=127.0.0.1:9000 MINIO_BUCKET=upload go test -run TestS3Storage
+func TestS3Storage(t *testing.T) {
+	if os.Getenv("MINIO_ENDPOINT") == "" {
+		t.Skip("MINIO_ENDPOINT not set")
+	}
+	upload := app.GlobalDefault().Service.Upload
+	upload.Driver = app.DriverS3
+	upload.S3.Endpoint = os.Getenv("MINIO_ENDPOINT")
+	if bucket := os.Getenv("MINIO_BUCKET"); bucket != "" {
+		upload.S3.Bucket = bucket
+	}
+	if key := os.Getenv("MINIO_ACCESS_KEY"); key != "" {
+		upload.S3.AccessKey = key
+		upload.S3.SecretKey = os.Getenv("MINIO_SECRET_KEY")
+	}
+	s3, err := app.NewStorage(upload)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	testStorage(t, s3)
+}
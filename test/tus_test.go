@@ -0,0 +1,173 @@
+package test
+
+import (
+	`bytes`
+	`encoding/base64`
+	`fmt`
+	`net`
+	`net/http`
+	`net/http/httptest`
+	`os`
+	`path/filepath`
+	`strconv`
+	`strings`
+	`testing`
+	`time`
+	
+	`github.com/alicebob/miniredis/v2`
+	`github.com/chaodoing/figure/app`
+	`github.com/kataras/iris/v12`
+)
+
+func TestTus(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("DIR", dir)
+	server := miniredis.RunT(t)
+	addr, err := net.ResolveTCPAddr("tcp", server.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	upload := filepath.Join(dir, "upload")
+	service := `, "upload": {"extensions": [".png", ".txt"], "mimes": ["image/*", "text/plain"], "driver": "local", "resource": {"url": "/upload", "dir": %q}, "tus": {"maximum": %d, "expire": 60}}`
+	port := freePort(t)
+	boot, err := app.New(bootConfig(t, dir, port, addr, fmt.Sprintf(service, upload, 1)), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer boot.Global.Close()
+	var application *iris.Application
+	boot.Tus("/files").Handle(func(value *iris.Application) {
+		application = value
+	})
+	if err = application.Build(); err != nil {
+		t.Fatal(err)
+	}
+	request := func(method, target string, headers map[string]string, body []byte, status int) http.Header {
+		t.Helper()
+		req := httptest.NewRequest(method, target, bytes.NewReader(body))
+		req.Header.Set("Tus-Resumable", app.TusVersion)
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		res := httptest.NewRecorder()
+		application.ServeHTTP(res, req)
+		if res.Code != status {
+			t.Fatalf("%s %s status %d, want %d", method, target, res.Code, status)
+		}
+		return res.Header()
+	}
+	create := func(name string, length int, status int) string {
+		t.Helper()
+		metadata := "filename " + base64.StdEncoding.EncodeToString([]byte(name))
+		header := request(http.MethodPost, "/files", map[string]string{"Upload-Length": strconv.Itoa(length), "Upload-Metadata": metadata}, nil, status)
+		return header.Get("Location")
+	}
+	patch := func(location string, offset int, body []byte, status int) http.Header {
+		t.Helper()
+		return request(http.MethodPatch, location, map[string]string{"Content-Type": "application/offset+octet-stream", "Upload-Offset": strconv.Itoa(offset)}, body, status)
+	}
+	
+	// 协议版本和扩展
+	if header := request(http.MethodOptions, "/files", nil, nil, http.StatusNoContent); header.Get("Tus-Extension") != app.TusExtension || header.Get("Tus-Max-Size") != strconv.Itoa(1<<20) {
+		t.Errorf("options %v", header)
+	}
+	request(http.MethodPost, "/files", map[string]string{"Tus-Resumable": "0.2.0", "Upload-Length": "1"}, nil, http.StatusPreconditionFailed)
+	// 创建时校验大小和扩展名
+	create("large.png", 1<<20+1, http.StatusRequestEntityTooLarge)
+	create("run.exe", 10, http.StatusUnsupportedMediaType)
+	request(http.MethodPost, "/files", map[string]string{"Upload-Length": "-1"}, nil, http.StatusBadRequest)
+	
+	// 分两次上传，偏移量不一致时返回 409
+	content := png(2048)
+	location := create("image.png", len(content), http.StatusCreated)
+	if !strings.HasPrefix(location, "/files/") {
+		t.Fatalf("location %q", location)
+	}
+	if header := request(http.MethodHead, location, nil, nil, http.StatusOK); header.Get("Upload-Offset") != "0" || header.Get("Upload-Length") != "2048" || header.Get("Upload-Expires") == "" {
+		t.Errorf("head %v", header)
+	}
+	if header := patch(location, 0, content[:1000], http.StatusNoContent); header.Get("Upload-Offset") != "1000" {
+		t.Errorf("patch %v", header)
+	}
+	patch(location, 0, content[1000:], http.StatusConflict)
+	request(http.MethodPatch, location, map[string]string{"Content-Type": "application/json", "Upload-Offset": "1000"}, content[1000:], http.StatusUnsupportedMediaType)
+	if header := request(http.MethodHead, location, nil, nil, http.StatusOK); header.Get("Upload-Offset") != "1000" {
+		t.Errorf("head %v", header)
+	}
+	// 其它请求持有锁时返回 409，Redis 出错时返回 500
+	id := strings.TrimPrefix(location, "/files/")
+	if err = server.Set("tus:"+id+":lock", "other"); err != nil {
+		t.Fatal(err)
+	}
+	patch(location, 1000, content[1000:], http.StatusConflict)
+	server.Del("tus:" + id + ":lock")
+	server.SetError("LOADING")
+	patch(location, 1000, content[1000:], http.StatusInternalServerError)
+	server.SetError("")
+	header := patch(location, 1000, content[1000:], http.StatusNoContent)
+	if header.Get("Upload-Offset") != "2048" || !strings.HasPrefix(header.Get("Upload-Url"), "/upload/") {
+		t.Errorf("complete %v", header)
+	}
+	if server.Exists("tus:" + id + ":lock") {
+		t.Error("lock not released")
+	}
+	stored, err := os.ReadFile(filepath.Join(upload, strings.TrimPrefix(header.Get("Upload-Url"), "/upload/")))
+	if err != nil || !bytes.Equal(stored, content) {
+		t.Errorf("stored %d bytes %v", len(stored), err)
+	}
+	if head := request(http.MethodHead, location, nil, nil, http.StatusOK); head.Get("Upload-Url") != header.Get("Upload-Url") {
+		t.Errorf("head after complete %v", head)
+	}
+	patch(location, 2048, nil, http.StatusConflict)
+	
+	// 空文件创建时直接完成
+	empty := create("empty.txt", 0, http.StatusCreated)
+	if head := request(http.MethodHead, empty, nil, nil, http.StatusOK); !strings.HasSuffix(head.Get("Upload-Url"), ".txt") || head.Get("Upload-Offset") != "0" {
+		t.Errorf("empty %v", head)
+	}
+	
+	// 终止上传时删除状态和未完成的文件
+	location = create("cancel.png", 10, http.StatusCreated)
+	// 未完成的文件保存在暂存目录中，不在对外访问的上传目录中
+	partial := filepath.Join(dir, "runtime", "tus", strings.TrimPrefix(location, "/files/"))
+	if _, err = os.Stat(partial); err != nil {
+		t.Fatal(err)
+	}
+	request(http.MethodDelete, location, nil, nil, http.StatusNoContent)
+	request(http.MethodHead, location, nil, nil, http.StatusNotFound)
+	request(http.MethodDelete, location, nil, nil, http.StatusNotFound)
+	if _, err = os.Stat(partial); !os.IsNotExist(err) {
+		t.Errorf("partial file %v", err)
+	}
+	
+	// 过期后上传不存在
+	location = create("expire.png", 10, http.StatusCreated)
+	server.FastForward(61 * time.Second)
+	request(http.MethodHead, location, nil, nil, http.StatusNotFound)
+	patch(location, 0, png(10), http.StatusNotFound)
+	
+	// 重新加载后使用新的大小限制
+	bootConfig(t, dir, port, addr, fmt.Sprintf(service, upload, 2))
+	if err = boot.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if header := request(http.MethodOptions, "/files", nil, nil, http.StatusNoContent); header.Get("Tus-Max-Size") != strconv.Itoa(2<<20) {
+		t.Errorf("options after reload %v", header)
+	}
+	create("reload.png", 1<<20+1, http.StatusCreated)
+}
+
+func TestTusValidate(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("DIR", dir)
+	global := app.GlobalDefault()
+	global.Service.Upload.Resource.Dir = filepath.Join(dir, "upload")
+	global.Service.Upload.Tus.Dir = filepath.Join(dir, "upload", ".tus")
+	if err := global.Validate(); err == nil || !strings.Contains(err.Error(), "service.upload.tus.dir") {
+		t.Errorf("staging inside upload dir %v", err)
+	}
+	global.Service.Upload.Tus.Dir = filepath.Join(dir, "uploads")
+	if err := global.Validate(); err != nil && strings.Contains(err.Error(), "service.upload.tus.dir") {
+		t.Errorf("staging outside upload dir %v", err)
+	}
+}
@@ -0,0 +1,135 @@
+package test
+
+import (
+	`bytes`
+	`crypto/sha256`
+	`encoding/hex`
+	`encoding/json`
+	`fmt`
+	`mime/multipart`
+	`net/http`
+	`net/http/httptest`
+	`os`
+	`path/filepath`
+	`strings`
+	`testing`
+	`time`
+	
+	`github.com/chaodoing/figure/app`
+	`github.com/kataras/iris/v12`
+)
+
+// png 返回以 PNG 文件头开始、指定长度的内容
+func png(size int) []byte {
+	content := append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{0}, size)...)
+	return content[:size]
+}
+
+// multipartBody 创建包含文件的 multipart/form-data 请求体
+func multipartBody(t *testing.T, files map[string][]byte) (*bytes.Buffer, string) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for name, content := range files {
+		part, err := writer.CreateFormFile("file", name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = part.Write(content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &body, writer.FormDataContentType()
+}
+
+func TestUpload(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("DIR", dir)
+	upload := filepath.Join(dir, "upload")
+	service := fmt.Sprintf(`, "upload": {"maximum": 1, "extensions": ["png", ".gif"], "mimes": ["image/*"], "driver": "local", "resource": {"url": "/upload", "dir": %q}}`, upload)
+	boot, err := app.New(bootConfig(t, dir, freePort(t), pong(t), service), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer boot.Global.Close()
+	var application *iris.Application
+	boot.Upload("/upload").Handle(func(value *iris.Application) {
+		application = value
+	})
+	if err = application.Build(); err != nil {
+		t.Fatal(err)
+	}
+	post := func(files map[string][]byte, status int) (value reply) {
+		t.Helper()
+		body, kind := multipartBody(t, files)
+		req := httptest.NewRequest(http.MethodPost, "/upload", body)
+		req.Header.Set("Content-Type", kind)
+		req.Header.Set("Accept", "application/json")
+		res := httptest.NewRecorder()
+		application.ServeHTTP(res, req)
+		if res.Code != status {
+			t.Fatalf("status %d %s", res.Code, res.Body)
+		}
+		if err := json.Unmarshal(res.Body.Bytes(), &value); err != nil {
+			t.Fatal(err, res.Body)
+		}
+		return
+	}
+	
+	// 按内容摘要命名，相同内容只保存一份
+	content := png(1024)
+	sum := sha256.Sum256(content)
+	hash := hex.EncodeToString(sum[:])
+	key := time.Now().Format("2006/01/02") + "/" + hash + ".png"
+	for _, name := range []string{"a.png", "B.PNG"} {
+		value := post(map[string][]byte{name: content}, http.StatusOK)
+		var files []app.Uploaded
+		if err = json.Unmarshal(value.Data, &files); err != nil {
+			t.Fatal(err)
+		}
+		if len(files) != 1 || files[0].Name != name || files[0].Hash != hash || files[0].Mime != "image/png" || files[0].Size != 1024 || files[0].Url != "/upload/"+key {
+			t.Errorf("uploaded %+v", files)
+		}
+	}
+	entries, err := os.ReadDir(filepath.Join(upload, filepath.FromSlash(filepath.Dir(key))))
+	if err != nil || len(entries) != 1 || entries[0].Name() != hash+".png" {
+		t.Errorf("stored %v %v", entries, err)
+	}
+	// 扩展名、内容类型和大小限制
+	cases := []struct {
+		name    string
+		content []byte
+		status  int
+		message string
+	}{
+		{"run.exe", png(64), http.StatusUnsupportedMediaType, app.ErrUploadExtension.Error()},
+		{"evil.png", []byte("<html><script>alert(1)</script></html>"), http.StatusUnsupportedMediaType, app.ErrUploadMime.Error()},
+		{"large.png", png(1<<20 + 1<<19), http.StatusRequestEntityTooLarge, app.ErrUploadTooLarge.Error()},
+	}
+	for _, c := range cases {
+		value := post(map[string][]byte{c.name: c.content}, c.status)
+		if value.Code != c.status || !strings.Contains(value.Message, c.message) {
+			t.Errorf("%s %+v", c.name, value)
+		}
+	}
+	if value := post(nil, http.StatusBadRequest); value.Message != app.ErrUploadEmpty.Error() {
+		t.Errorf("empty %+v", value)
+	}
+}
+
+func TestUploadValidate(t *testing.T) {
+	global := app.GlobalDefault()
+	global.Service.Upload.Driver = app.DriverS3
+	global.Service.Upload.S3.Endpoint = "https://s3.example.com/path"
+	global.Service.Upload.S3.Bucket = "bucket"
+	// 存储配置错误由 Validate 报告，而不是在注册路由时 panic
+	if err := global.Validate(); err == nil || !strings.Contains(err.Error(), "service.upload.s3.endpoint") {
+		t.Errorf("invalid endpoint %v", err)
+	}
+	global.Service.Upload.S3.Endpoint = "s3.example.com"
+	if err := global.Validate(); err != nil && strings.Contains(err.Error(), "service.upload.s3") {
+		t.Errorf("valid endpoint %v", err)
+	}
+}
@@ -0,0 +1,49 @@
+package test
+
+import (
+	`errors`
+	`os`
+	`path/filepath`
+	`testing`
+	
+	`github.com/chaodoing/figure/app`
+)
+
+func TestValidate(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("DIR", dir)
+	// 默认配置在空的 DIR 中同样通过校验，没有使用模板时不要求模板目录存在
+	global := app.GlobalDefault()
+	if err := global.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if entries, err := os.ReadDir(dir); err != nil || len(entries) != 0 {
+		t.Errorf("validate created %v %v", entries, err)
+	}
+	global.Service.Port = 0
+	global.Service.Log.Level = "verbose"
+	global.Service.Template.Delimit = []string{"{{"}
+	global.Service.Template.Dir = filepath.Join(dir, "missing")
+	global.MySQL.Name = ""
+	global.MySQL.Logger.Level = "debug"
+	err := global.Validate()
+	var invalid app.ValidationError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("unexpected error %v", err)
+	}
+	fields := make(map[string]bool)
+	for _, item := range invalid {
+		fields[item.Field] = true
+	}
+	for _, field := range []string{"service.port", "service.log.level", "service.template.delimit", "mysql.name", "mysql.logger.level"} {
+		if !fields[field] {
+			t.Errorf("%s not reported", field)
+		}
+	}
+	if fields["service.template.dir"] {
+		t.Error("missing template dir reported")
+	}
+	if len(invalid) != 5 {
+		t.Error(err)
+	}
+}
@@ -1,9 +1,15 @@
 package toolkit
 
 import (
+	`bytes`
 	`encoding/json`
 	`encoding/xml`
+	`fmt`
 	`os`
+	
+	`github.com/BurntSushi/toml`
+	encoder `github.com/zwgblue/yaml-encoder`
+	`gopkg.in/yaml.v2`
 )
 
 // ReadJSON 读取JSON
@@ -59,3 +65,98 @@ func SaveXML(data interface{}, file string) error {
 	}
 	return nil
 }
+
+// SaveYAML 存储YAML文件，结构体字段的 comment 标签会写为字段注释
+func SaveYAML(data interface{}, file string) error {
+	yamlByte, err := encoder.NewEncoder(data, encoder.WithComments(encoder.CommentsOnHead)).Encode()
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(os.ExpandEnv(file), yamlByte, 0666); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ReadYAML 读取YAML文件
+func ReadYAML(file string, data interface{}) (err error) {
+	var content []byte
+	content, err = os.ReadFile(os.ExpandEnv(file))
+	if err != nil {
+		return err
+	}
+	return yaml.Unmarshal(content, data)
+}
+
+// MarshalTOML 将数据编码为TOML，键名使用结构体的 yaml 标签，与 .env.yml 保持一致
+func MarshalTOML(data interface{}) ([]byte, error) {
+	content, err := yaml.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	var tree map[interface{}]interface{}
+	if err = yaml.Unmarshal(content, &tree); err != nil {
+		return nil, err
+	}
+	var buffer bytes.Buffer
+	if err = toml.NewEncoder(&buffer).Encode(stringify(tree)); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// UnmarshalTOML 将TOML解码到 data 中，键名使用结构体的 yaml 标签
+func UnmarshalTOML(content []byte, data interface{}) error {
+	var tree map[string]interface{}
+	if err := toml.Unmarshal(content, &tree); err != nil {
+		return err
+	}
+	value, err := yaml.Marshal(tree)
+	if err != nil {
+		return err
+	}
+	return yaml.Unmarshal(value, data)
+}
+
+// ReadTOML 读取TOML文件
+func ReadTOML(file string, data interface{}) (err error) {
+	var content []byte
+	content, err = os.ReadFile(os.ExpandEnv(file))
+	if err != nil {
+		return err
+	}
+	return UnmarshalTOML(content, data)
+}
+
+// SaveTOML 存储TOML文件
+func SaveTOML(data interface{}, file string) error {
+	tomlByte, err := MarshalTOML(data)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(os.ExpandEnv(file), tomlByte, 0666); err != nil {
+		return err
+	}
+	return nil
+}
+
+// stringify 将YAML解码得到的 map[interface{}]interface{} 转换为TOML编码器支持的 map[string]interface{}，并移除空值
+func stringify(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[interface{}]interface{}:
+		data := make(map[string]interface{}, len(value))
+		for key, item := range value {
+			if item != nil {
+				data[fmt.Sprint(key)] = stringify(item)
+			}
+		}
+		return data
+	case []interface{}:
+		for i, item := range value {
+			value[i] = stringify(item)
+		}
+		return value
+	default:
+		return value
+	}
+}